@@ -0,0 +1,1382 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        v6.33.0
+// source: client.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// connector.entryHandler.entry
+type EntryReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	UserInfo      *EntryUserInfo         `protobuf:"bytes,2,opt,name=userInfo,proto3" json:"userInfo,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EntryReq) Reset() {
+	*x = EntryReq{}
+	mi := &file_client_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EntryReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EntryReq) ProtoMessage() {}
+
+func (x *EntryReq) ProtoReflect() protoreflect.Message {
+	mi := &file_client_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EntryReq.ProtoReflect.Descriptor instead.
+func (*EntryReq) Descriptor() ([]byte, []int) {
+	return file_client_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *EntryReq) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *EntryReq) GetUserInfo() *EntryUserInfo {
+	if x != nil {
+		return x.UserInfo
+	}
+	return nil
+}
+
+type EntryUserInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Nickname      string                 `protobuf:"bytes,1,opt,name=nickname,proto3" json:"nickname,omitempty"`
+	Avatar        string                 `protobuf:"bytes,2,opt,name=avatar,proto3" json:"avatar,omitempty"`
+	Sex           int32                  `protobuf:"varint,3,opt,name=sex,proto3" json:"sex,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EntryUserInfo) Reset() {
+	*x = EntryUserInfo{}
+	mi := &file_client_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EntryUserInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EntryUserInfo) ProtoMessage() {}
+
+func (x *EntryUserInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_client_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EntryUserInfo.ProtoReflect.Descriptor instead.
+func (*EntryUserInfo) Descriptor() ([]byte, []int) {
+	return file_client_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *EntryUserInfo) GetNickname() string {
+	if x != nil {
+		return x.Nickname
+	}
+	return ""
+}
+
+func (x *EntryUserInfo) GetAvatar() string {
+	if x != nil {
+		return x.Avatar
+	}
+	return ""
+}
+
+func (x *EntryUserInfo) GetSex() int32 {
+	if x != nil {
+		return x.Sex
+	}
+	return 0
+}
+
+// hall.userHandler.updateUserAddress
+type UpdateUserAddressReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Address       string                 `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Location      string                 `protobuf:"bytes,2,opt,name=location,proto3" json:"location,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateUserAddressReq) Reset() {
+	*x = UpdateUserAddressReq{}
+	mi := &file_client_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateUserAddressReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateUserAddressReq) ProtoMessage() {}
+
+func (x *UpdateUserAddressReq) ProtoReflect() protoreflect.Message {
+	mi := &file_client_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateUserAddressReq.ProtoReflect.Descriptor instead.
+func (*UpdateUserAddressReq) Descriptor() ([]byte, []int) {
+	return file_client_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *UpdateUserAddressReq) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *UpdateUserAddressReq) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+// game.unionHandler.createRoom
+type CreateRoomReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UnionID       int64                  `protobuf:"varint,1,opt,name=unionID,proto3" json:"unionID,omitempty"`
+	GameRuleID    string                 `protobuf:"bytes,2,opt,name=gameRuleID,proto3" json:"gameRuleID,omitempty"`
+	GameRule      *GameRule              `protobuf:"bytes,3,opt,name=gameRule,proto3" json:"gameRule,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateRoomReq) Reset() {
+	*x = CreateRoomReq{}
+	mi := &file_client_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateRoomReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateRoomReq) ProtoMessage() {}
+
+func (x *CreateRoomReq) ProtoReflect() protoreflect.Message {
+	mi := &file_client_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateRoomReq.ProtoReflect.Descriptor instead.
+func (*CreateRoomReq) Descriptor() ([]byte, []int) {
+	return file_client_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CreateRoomReq) GetUnionID() int64 {
+	if x != nil {
+		return x.UnionID
+	}
+	return 0
+}
+
+func (x *CreateRoomReq) GetGameRuleID() string {
+	if x != nil {
+		return x.GameRuleID
+	}
+	return ""
+}
+
+func (x *CreateRoomReq) GetGameRule() *GameRule {
+	if x != nil {
+		return x.GameRule
+	}
+	return nil
+}
+
+type GameRule struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	AddScores      []int32                `protobuf:"varint,1,rep,packed,name=addScores,proto3" json:"addScores,omitempty"`
+	BaseScore      int32                  `protobuf:"varint,2,opt,name=baseScore,proto3" json:"baseScore,omitempty"`
+	Bureau         int32                  `protobuf:"varint,3,opt,name=bureau,proto3" json:"bureau,omitempty"`
+	CanEnter       bool                   `protobuf:"varint,4,opt,name=canEnter,proto3" json:"canEnter,omitempty"`
+	CanTrust       bool                   `protobuf:"varint,5,opt,name=canTrust,proto3" json:"canTrust,omitempty"`
+	CanWatch       bool                   `protobuf:"varint,6,opt,name=canWatch,proto3" json:"canWatch,omitempty"`
+	Cuopai         bool                   `protobuf:"varint,7,opt,name=cuopai,proto3" json:"cuopai,omitempty"`
+	Fangzuobi      bool                   `protobuf:"varint,8,opt,name=fangzuobi,proto3" json:"fangzuobi,omitempty"`
+	Yuyin          bool                   `protobuf:"varint,9,opt,name=yuyin,proto3" json:"yuyin,omitempty"`
+	GameFrameType  int32                  `protobuf:"varint,10,opt,name=gameFrameType,proto3" json:"gameFrameType,omitempty"`
+	GameType       int32                  `protobuf:"varint,11,opt,name=gameType,proto3" json:"gameType,omitempty"`
+	MaxPlayerCount int32                  `protobuf:"varint,12,opt,name=maxPlayerCount,proto3" json:"maxPlayerCount,omitempty"`
+	MinPlayerCount int32                  `protobuf:"varint,13,opt,name=minPlayerCount,proto3" json:"minPlayerCount,omitempty"`
+	MaxScore       int32                  `protobuf:"varint,14,opt,name=maxScore,proto3" json:"maxScore,omitempty"`
+	RoundType      int32                  `protobuf:"varint,15,opt,name=roundType,proto3" json:"roundType,omitempty"`
+	PayDiamond     int32                  `protobuf:"varint,16,opt,name=payDiamond,proto3" json:"payDiamond,omitempty"`
+	PayType        int32                  `protobuf:"varint,17,opt,name=payType,proto3" json:"payType,omitempty"`
+	RoomType       int32                  `protobuf:"varint,18,opt,name=roomType,proto3" json:"roomType,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GameRule) Reset() {
+	*x = GameRule{}
+	mi := &file_client_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GameRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GameRule) ProtoMessage() {}
+
+func (x *GameRule) ProtoReflect() protoreflect.Message {
+	mi := &file_client_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GameRule.ProtoReflect.Descriptor instead.
+func (*GameRule) Descriptor() ([]byte, []int) {
+	return file_client_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GameRule) GetAddScores() []int32 {
+	if x != nil {
+		return x.AddScores
+	}
+	return nil
+}
+
+func (x *GameRule) GetBaseScore() int32 {
+	if x != nil {
+		return x.BaseScore
+	}
+	return 0
+}
+
+func (x *GameRule) GetBureau() int32 {
+	if x != nil {
+		return x.Bureau
+	}
+	return 0
+}
+
+func (x *GameRule) GetCanEnter() bool {
+	if x != nil {
+		return x.CanEnter
+	}
+	return false
+}
+
+func (x *GameRule) GetCanTrust() bool {
+	if x != nil {
+		return x.CanTrust
+	}
+	return false
+}
+
+func (x *GameRule) GetCanWatch() bool {
+	if x != nil {
+		return x.CanWatch
+	}
+	return false
+}
+
+func (x *GameRule) GetCuopai() bool {
+	if x != nil {
+		return x.Cuopai
+	}
+	return false
+}
+
+func (x *GameRule) GetFangzuobi() bool {
+	if x != nil {
+		return x.Fangzuobi
+	}
+	return false
+}
+
+func (x *GameRule) GetYuyin() bool {
+	if x != nil {
+		return x.Yuyin
+	}
+	return false
+}
+
+func (x *GameRule) GetGameFrameType() int32 {
+	if x != nil {
+		return x.GameFrameType
+	}
+	return 0
+}
+
+func (x *GameRule) GetGameType() int32 {
+	if x != nil {
+		return x.GameType
+	}
+	return 0
+}
+
+func (x *GameRule) GetMaxPlayerCount() int32 {
+	if x != nil {
+		return x.MaxPlayerCount
+	}
+	return 0
+}
+
+func (x *GameRule) GetMinPlayerCount() int32 {
+	if x != nil {
+		return x.MinPlayerCount
+	}
+	return 0
+}
+
+func (x *GameRule) GetMaxScore() int32 {
+	if x != nil {
+		return x.MaxScore
+	}
+	return 0
+}
+
+func (x *GameRule) GetRoundType() int32 {
+	if x != nil {
+		return x.RoundType
+	}
+	return 0
+}
+
+func (x *GameRule) GetPayDiamond() int32 {
+	if x != nil {
+		return x.PayDiamond
+	}
+	return 0
+}
+
+func (x *GameRule) GetPayType() int32 {
+	if x != nil {
+		return x.PayType
+	}
+	return 0
+}
+
+func (x *GameRule) GetRoomType() int32 {
+	if x != nil {
+		return x.RoomType
+	}
+	return 0
+}
+
+// game.unionHandler.joinRoom
+type JoinRoomReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RoomID        string                 `protobuf:"bytes,1,opt,name=roomID,proto3" json:"roomID,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JoinRoomReq) Reset() {
+	*x = JoinRoomReq{}
+	mi := &file_client_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JoinRoomReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JoinRoomReq) ProtoMessage() {}
+
+func (x *JoinRoomReq) ProtoReflect() protoreflect.Message {
+	mi := &file_client_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JoinRoomReq.ProtoReflect.Descriptor instead.
+func (*JoinRoomReq) Descriptor() ([]byte, []int) {
+	return file_client_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *JoinRoomReq) GetRoomID() string {
+	if x != nil {
+		return x.RoomID
+	}
+	return ""
+}
+
+// game.gameHandler.roomMessageNotify
+type RoomMessageReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          int32                  `protobuf:"varint,1,opt,name=type,proto3" json:"type,omitempty"`
+	Data          *RoomMessageData       `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RoomMessageReq) Reset() {
+	*x = RoomMessageReq{}
+	mi := &file_client_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RoomMessageReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RoomMessageReq) ProtoMessage() {}
+
+func (x *RoomMessageReq) ProtoReflect() protoreflect.Message {
+	mi := &file_client_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RoomMessageReq.ProtoReflect.Descriptor instead.
+func (*RoomMessageReq) Descriptor() ([]byte, []int) {
+	return file_client_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *RoomMessageReq) GetType() int32 {
+	if x != nil {
+		return x.Type
+	}
+	return 0
+}
+
+func (x *RoomMessageReq) GetData() *RoomMessageData {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type RoomMessageData struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	IsReady       bool                   `protobuf:"varint,1,opt,name=isReady,proto3" json:"isReady,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RoomMessageData) Reset() {
+	*x = RoomMessageData{}
+	mi := &file_client_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RoomMessageData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RoomMessageData) ProtoMessage() {}
+
+func (x *RoomMessageData) ProtoReflect() protoreflect.Message {
+	mi := &file_client_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RoomMessageData.ProtoReflect.Descriptor instead.
+func (*RoomMessageData) Descriptor() ([]byte, []int) {
+	return file_client_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RoomMessageData) GetIsReady() bool {
+	if x != nil {
+		return x.IsReady
+	}
+	return false
+}
+
+// 以下push按pushRouter注册，1号字段都是pushRouter
+type UpdateUserInfoPush struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PushRouter    string                 `protobuf:"bytes,1,opt,name=pushRouter,proto3" json:"pushRouter,omitempty"`
+	RoomID        string                 `protobuf:"bytes,2,opt,name=roomID,proto3" json:"roomID,omitempty"`
+	Gold          int64                  `protobuf:"varint,3,opt,name=gold,proto3" json:"gold,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateUserInfoPush) Reset() {
+	*x = UpdateUserInfoPush{}
+	mi := &file_client_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateUserInfoPush) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateUserInfoPush) ProtoMessage() {}
+
+func (x *UpdateUserInfoPush) ProtoReflect() protoreflect.Message {
+	mi := &file_client_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateUserInfoPush.ProtoReflect.Descriptor instead.
+func (*UpdateUserInfoPush) Descriptor() ([]byte, []int) {
+	return file_client_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *UpdateUserInfoPush) GetPushRouter() string {
+	if x != nil {
+		return x.PushRouter
+	}
+	return ""
+}
+
+func (x *UpdateUserInfoPush) GetRoomID() string {
+	if x != nil {
+		return x.RoomID
+	}
+	return ""
+}
+
+func (x *UpdateUserInfoPush) GetGold() int64 {
+	if x != nil {
+		return x.Gold
+	}
+	return 0
+}
+
+type SelfEntryRoomPush struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PushRouter    string                 `protobuf:"bytes,1,opt,name=pushRouter,proto3" json:"pushRouter,omitempty"`
+	GameType      int32                  `protobuf:"varint,2,opt,name=gameType,proto3" json:"gameType,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SelfEntryRoomPush) Reset() {
+	*x = SelfEntryRoomPush{}
+	mi := &file_client_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SelfEntryRoomPush) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SelfEntryRoomPush) ProtoMessage() {}
+
+func (x *SelfEntryRoomPush) ProtoReflect() protoreflect.Message {
+	mi := &file_client_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SelfEntryRoomPush.ProtoReflect.Descriptor instead.
+func (*SelfEntryRoomPush) Descriptor() ([]byte, []int) {
+	return file_client_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SelfEntryRoomPush) GetPushRouter() string {
+	if x != nil {
+		return x.PushRouter
+	}
+	return ""
+}
+
+func (x *SelfEntryRoomPush) GetGameType() int32 {
+	if x != nil {
+		return x.GameType
+	}
+	return 0
+}
+
+type RoomMessagePush struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PushRouter    string                 `protobuf:"bytes,1,opt,name=pushRouter,proto3" json:"pushRouter,omitempty"`
+	Type          int32                  `protobuf:"varint,2,opt,name=type,proto3" json:"type,omitempty"`
+	Data          *RoomMessagePushData   `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RoomMessagePush) Reset() {
+	*x = RoomMessagePush{}
+	mi := &file_client_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RoomMessagePush) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RoomMessagePush) ProtoMessage() {}
+
+func (x *RoomMessagePush) ProtoReflect() protoreflect.Message {
+	mi := &file_client_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RoomMessagePush.ProtoReflect.Descriptor instead.
+func (*RoomMessagePush) Descriptor() ([]byte, []int) {
+	return file_client_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *RoomMessagePush) GetPushRouter() string {
+	if x != nil {
+		return x.PushRouter
+	}
+	return ""
+}
+
+func (x *RoomMessagePush) GetType() int32 {
+	if x != nil {
+		return x.Type
+	}
+	return 0
+}
+
+func (x *RoomMessagePush) GetData() *RoomMessagePushData {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+// 房间推送的data，不同type使用其中的部分字段
+type RoomMessagePushData struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChairID       int32                  `protobuf:"varint,1,opt,name=chairID,proto3" json:"chairID,omitempty"`
+	RoomUserInfo  *RoomUser              `protobuf:"bytes,2,opt,name=roomUserInfo,proto3" json:"roomUserInfo,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RoomMessagePushData) Reset() {
+	*x = RoomMessagePushData{}
+	mi := &file_client_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RoomMessagePushData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RoomMessagePushData) ProtoMessage() {}
+
+func (x *RoomMessagePushData) ProtoReflect() protoreflect.Message {
+	mi := &file_client_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RoomMessagePushData.ProtoReflect.Descriptor instead.
+func (*RoomMessagePushData) Descriptor() ([]byte, []int) {
+	return file_client_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *RoomMessagePushData) GetChairID() int32 {
+	if x != nil {
+		return x.ChairID
+	}
+	return 0
+}
+
+func (x *RoomMessagePushData) GetRoomUserInfo() *RoomUser {
+	if x != nil {
+		return x.RoomUserInfo
+	}
+	return nil
+}
+
+type RoomUser struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserInfo      *RoomUserInfo          `protobuf:"bytes,1,opt,name=userInfo,proto3" json:"userInfo,omitempty"`
+	ChairID       int32                  `protobuf:"varint,2,opt,name=chairID,proto3" json:"chairID,omitempty"`
+	UserStatus    int32                  `protobuf:"varint,3,opt,name=userStatus,proto3" json:"userStatus,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RoomUser) Reset() {
+	*x = RoomUser{}
+	mi := &file_client_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RoomUser) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RoomUser) ProtoMessage() {}
+
+func (x *RoomUser) ProtoReflect() protoreflect.Message {
+	mi := &file_client_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RoomUser.ProtoReflect.Descriptor instead.
+func (*RoomUser) Descriptor() ([]byte, []int) {
+	return file_client_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *RoomUser) GetUserInfo() *RoomUserInfo {
+	if x != nil {
+		return x.UserInfo
+	}
+	return nil
+}
+
+func (x *RoomUser) GetChairID() int32 {
+	if x != nil {
+		return x.ChairID
+	}
+	return 0
+}
+
+func (x *RoomUser) GetUserStatus() int32 {
+	if x != nil {
+		return x.UserStatus
+	}
+	return 0
+}
+
+type RoomUserInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Uid           string                 `protobuf:"bytes,1,opt,name=uid,proto3" json:"uid,omitempty"`
+	Nickname      string                 `protobuf:"bytes,2,opt,name=nickname,proto3" json:"nickname,omitempty"`
+	Avatar        string                 `protobuf:"bytes,3,opt,name=avatar,proto3" json:"avatar,omitempty"`
+	Gold          int64                  `protobuf:"varint,4,opt,name=gold,proto3" json:"gold,omitempty"`
+	FrontendId    string                 `protobuf:"bytes,5,opt,name=frontendId,proto3" json:"frontendId,omitempty"`
+	Address       string                 `protobuf:"bytes,6,opt,name=address,proto3" json:"address,omitempty"`
+	Location      string                 `protobuf:"bytes,7,opt,name=location,proto3" json:"location,omitempty"`
+	LastLoginIP   string                 `protobuf:"bytes,8,opt,name=lastLoginIP,proto3" json:"lastLoginIP,omitempty"`
+	Sex           int32                  `protobuf:"varint,9,opt,name=sex,proto3" json:"sex,omitempty"`
+	Score         int32                  `protobuf:"varint,10,opt,name=score,proto3" json:"score,omitempty"`
+	SpreaderID    string                 `protobuf:"bytes,11,opt,name=spreaderID,proto3" json:"spreaderID,omitempty"`
+	ProhibitGame  bool                   `protobuf:"varint,12,opt,name=prohibitGame,proto3" json:"prohibitGame,omitempty"`
+	RoomID        string                 `protobuf:"bytes,13,opt,name=roomID,proto3" json:"roomID,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RoomUserInfo) Reset() {
+	*x = RoomUserInfo{}
+	mi := &file_client_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RoomUserInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RoomUserInfo) ProtoMessage() {}
+
+func (x *RoomUserInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_client_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RoomUserInfo.ProtoReflect.Descriptor instead.
+func (*RoomUserInfo) Descriptor() ([]byte, []int) {
+	return file_client_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *RoomUserInfo) GetUid() string {
+	if x != nil {
+		return x.Uid
+	}
+	return ""
+}
+
+func (x *RoomUserInfo) GetNickname() string {
+	if x != nil {
+		return x.Nickname
+	}
+	return ""
+}
+
+func (x *RoomUserInfo) GetAvatar() string {
+	if x != nil {
+		return x.Avatar
+	}
+	return ""
+}
+
+func (x *RoomUserInfo) GetGold() int64 {
+	if x != nil {
+		return x.Gold
+	}
+	return 0
+}
+
+func (x *RoomUserInfo) GetFrontendId() string {
+	if x != nil {
+		return x.FrontendId
+	}
+	return ""
+}
+
+func (x *RoomUserInfo) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *RoomUserInfo) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+func (x *RoomUserInfo) GetLastLoginIP() string {
+	if x != nil {
+		return x.LastLoginIP
+	}
+	return ""
+}
+
+func (x *RoomUserInfo) GetSex() int32 {
+	if x != nil {
+		return x.Sex
+	}
+	return 0
+}
+
+func (x *RoomUserInfo) GetScore() int32 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *RoomUserInfo) GetSpreaderID() string {
+	if x != nil {
+		return x.SpreaderID
+	}
+	return ""
+}
+
+func (x *RoomUserInfo) GetProhibitGame() bool {
+	if x != nil {
+		return x.ProhibitGame
+	}
+	return false
+}
+
+func (x *RoomUserInfo) GetRoomID() string {
+	if x != nil {
+		return x.RoomID
+	}
+	return ""
+}
+
+type GameMessagePush struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PushRouter    string                 `protobuf:"bytes,1,opt,name=pushRouter,proto3" json:"pushRouter,omitempty"`
+	Type          int32                  `protobuf:"varint,2,opt,name=type,proto3" json:"type,omitempty"`
+	Data          *GameMessagePushData   `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GameMessagePush) Reset() {
+	*x = GameMessagePush{}
+	mi := &file_client_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GameMessagePush) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GameMessagePush) ProtoMessage() {}
+
+func (x *GameMessagePush) ProtoReflect() protoreflect.Message {
+	mi := &file_client_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GameMessagePush.ProtoReflect.Descriptor instead.
+func (*GameMessagePush) Descriptor() ([]byte, []int) {
+	return file_client_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GameMessagePush) GetPushRouter() string {
+	if x != nil {
+		return x.PushRouter
+	}
+	return ""
+}
+
+func (x *GameMessagePush) GetType() int32 {
+	if x != nil {
+		return x.Type
+	}
+	return 0
+}
+
+func (x *GameMessagePush) GetData() *GameMessagePushData {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+// 游戏推送的data，发牌等proto表示不了的推送仍然是json
+type GameMessagePushData struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GameStatus    int32                  `protobuf:"varint,1,opt,name=gameStatus,proto3" json:"gameStatus,omitempty"`
+	Tick          int32                  `protobuf:"varint,2,opt,name=tick,proto3" json:"tick,omitempty"`
+	BankerChairID int32                  `protobuf:"varint,3,opt,name=bankerChairID,proto3" json:"bankerChairID,omitempty"`
+	CurBureau     int32                  `protobuf:"varint,4,opt,name=curBureau,proto3" json:"curBureau,omitempty"`
+	ChairID       int32                  `protobuf:"varint,5,opt,name=chairID,proto3" json:"chairID,omitempty"`
+	Score         int32                  `protobuf:"varint,6,opt,name=score,proto3" json:"score,omitempty"`
+	ChairScore    int32                  `protobuf:"varint,7,opt,name=chairScore,proto3" json:"chairScore,omitempty"`
+	Scores        int32                  `protobuf:"varint,8,opt,name=scores,proto3" json:"scores,omitempty"`
+	Type          int32                  `protobuf:"varint,9,opt,name=type,proto3" json:"type,omitempty"`
+	Round         int32                  `protobuf:"varint,10,opt,name=round,proto3" json:"round,omitempty"`
+	CurChairID    int32                  `protobuf:"varint,11,opt,name=curChairID,proto3" json:"curChairID,omitempty"`
+	CurScore      int32                  `protobuf:"varint,12,opt,name=curScore,proto3" json:"curScore,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GameMessagePushData) Reset() {
+	*x = GameMessagePushData{}
+	mi := &file_client_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GameMessagePushData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GameMessagePushData) ProtoMessage() {}
+
+func (x *GameMessagePushData) ProtoReflect() protoreflect.Message {
+	mi := &file_client_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GameMessagePushData.ProtoReflect.Descriptor instead.
+func (*GameMessagePushData) Descriptor() ([]byte, []int) {
+	return file_client_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GameMessagePushData) GetGameStatus() int32 {
+	if x != nil {
+		return x.GameStatus
+	}
+	return 0
+}
+
+func (x *GameMessagePushData) GetTick() int32 {
+	if x != nil {
+		return x.Tick
+	}
+	return 0
+}
+
+func (x *GameMessagePushData) GetBankerChairID() int32 {
+	if x != nil {
+		return x.BankerChairID
+	}
+	return 0
+}
+
+func (x *GameMessagePushData) GetCurBureau() int32 {
+	if x != nil {
+		return x.CurBureau
+	}
+	return 0
+}
+
+func (x *GameMessagePushData) GetChairID() int32 {
+	if x != nil {
+		return x.ChairID
+	}
+	return 0
+}
+
+func (x *GameMessagePushData) GetScore() int32 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *GameMessagePushData) GetChairScore() int32 {
+	if x != nil {
+		return x.ChairScore
+	}
+	return 0
+}
+
+func (x *GameMessagePushData) GetScores() int32 {
+	if x != nil {
+		return x.Scores
+	}
+	return 0
+}
+
+func (x *GameMessagePushData) GetType() int32 {
+	if x != nil {
+		return x.Type
+	}
+	return 0
+}
+
+func (x *GameMessagePushData) GetRound() int32 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *GameMessagePushData) GetCurChairID() int32 {
+	if x != nil {
+		return x.CurChairID
+	}
+	return 0
+}
+
+func (x *GameMessagePushData) GetCurScore() int32 {
+	if x != nil {
+		return x.CurScore
+	}
+	return 0
+}
+
+var File_client_proto protoreflect.FileDescriptor
+
+const file_client_proto_rawDesc = "" +
+	"\n" +
+	"\fclient.proto\"L\n" +
+	"\bEntryReq\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12*\n" +
+	"\buserInfo\x18\x02 \x01(\v2\x0e.EntryUserInfoR\buserInfo\"U\n" +
+	"\rEntryUserInfo\x12\x1a\n" +
+	"\bnickname\x18\x01 \x01(\tR\bnickname\x12\x16\n" +
+	"\x06avatar\x18\x02 \x01(\tR\x06avatar\x12\x10\n" +
+	"\x03sex\x18\x03 \x01(\x05R\x03sex\"L\n" +
+	"\x14UpdateUserAddressReq\x12\x18\n" +
+	"\aaddress\x18\x01 \x01(\tR\aaddress\x12\x1a\n" +
+	"\blocation\x18\x02 \x01(\tR\blocation\"p\n" +
+	"\rCreateRoomReq\x12\x18\n" +
+	"\aunionID\x18\x01 \x01(\x03R\aunionID\x12\x1e\n" +
+	"\n" +
+	"gameRuleID\x18\x02 \x01(\tR\n" +
+	"gameRuleID\x12%\n" +
+	"\bgameRule\x18\x03 \x01(\v2\t.GameRuleR\bgameRule\"\xa0\x04\n" +
+	"\bGameRule\x12\x1c\n" +
+	"\taddScores\x18\x01 \x03(\x05R\taddScores\x12\x1c\n" +
+	"\tbaseScore\x18\x02 \x01(\x05R\tbaseScore\x12\x16\n" +
+	"\x06bureau\x18\x03 \x01(\x05R\x06bureau\x12\x1a\n" +
+	"\bcanEnter\x18\x04 \x01(\bR\bcanEnter\x12\x1a\n" +
+	"\bcanTrust\x18\x05 \x01(\bR\bcanTrust\x12\x1a\n" +
+	"\bcanWatch\x18\x06 \x01(\bR\bcanWatch\x12\x16\n" +
+	"\x06cuopai\x18\a \x01(\bR\x06cuopai\x12\x1c\n" +
+	"\tfangzuobi\x18\b \x01(\bR\tfangzuobi\x12\x14\n" +
+	"\x05yuyin\x18\t \x01(\bR\x05yuyin\x12$\n" +
+	"\rgameFrameType\x18\n" +
+	" \x01(\x05R\rgameFrameType\x12\x1a\n" +
+	"\bgameType\x18\v \x01(\x05R\bgameType\x12&\n" +
+	"\x0emaxPlayerCount\x18\f \x01(\x05R\x0emaxPlayerCount\x12&\n" +
+	"\x0eminPlayerCount\x18\r \x01(\x05R\x0eminPlayerCount\x12\x1a\n" +
+	"\bmaxScore\x18\x0e \x01(\x05R\bmaxScore\x12\x1c\n" +
+	"\troundType\x18\x0f \x01(\x05R\troundType\x12\x1e\n" +
+	"\n" +
+	"payDiamond\x18\x10 \x01(\x05R\n" +
+	"payDiamond\x12\x18\n" +
+	"\apayType\x18\x11 \x01(\x05R\apayType\x12\x1a\n" +
+	"\broomType\x18\x12 \x01(\x05R\broomType\"%\n" +
+	"\vJoinRoomReq\x12\x16\n" +
+	"\x06roomID\x18\x01 \x01(\tR\x06roomID\"J\n" +
+	"\x0eRoomMessageReq\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\x05R\x04type\x12$\n" +
+	"\x04data\x18\x02 \x01(\v2\x10.RoomMessageDataR\x04data\"+\n" +
+	"\x0fRoomMessageData\x12\x18\n" +
+	"\aisReady\x18\x01 \x01(\bR\aisReady\"`\n" +
+	"\x12UpdateUserInfoPush\x12\x1e\n" +
+	"\n" +
+	"pushRouter\x18\x01 \x01(\tR\n" +
+	"pushRouter\x12\x16\n" +
+	"\x06roomID\x18\x02 \x01(\tR\x06roomID\x12\x12\n" +
+	"\x04gold\x18\x03 \x01(\x03R\x04gold\"O\n" +
+	"\x11SelfEntryRoomPush\x12\x1e\n" +
+	"\n" +
+	"pushRouter\x18\x01 \x01(\tR\n" +
+	"pushRouter\x12\x1a\n" +
+	"\bgameType\x18\x02 \x01(\x05R\bgameType\"o\n" +
+	"\x0fRoomMessagePush\x12\x1e\n" +
+	"\n" +
+	"pushRouter\x18\x01 \x01(\tR\n" +
+	"pushRouter\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\x05R\x04type\x12(\n" +
+	"\x04data\x18\x03 \x01(\v2\x14.RoomMessagePushDataR\x04data\"^\n" +
+	"\x13RoomMessagePushData\x12\x18\n" +
+	"\achairID\x18\x01 \x01(\x05R\achairID\x12-\n" +
+	"\froomUserInfo\x18\x02 \x01(\v2\t.RoomUserR\froomUserInfo\"o\n" +
+	"\bRoomUser\x12)\n" +
+	"\buserInfo\x18\x01 \x01(\v2\r.RoomUserInfoR\buserInfo\x12\x18\n" +
+	"\achairID\x18\x02 \x01(\x05R\achairID\x12\x1e\n" +
+	"\n" +
+	"userStatus\x18\x03 \x01(\x05R\n" +
+	"userStatus\"\xe4\x02\n" +
+	"\fRoomUserInfo\x12\x10\n" +
+	"\x03uid\x18\x01 \x01(\tR\x03uid\x12\x1a\n" +
+	"\bnickname\x18\x02 \x01(\tR\bnickname\x12\x16\n" +
+	"\x06avatar\x18\x03 \x01(\tR\x06avatar\x12\x12\n" +
+	"\x04gold\x18\x04 \x01(\x03R\x04gold\x12\x1e\n" +
+	"\n" +
+	"frontendId\x18\x05 \x01(\tR\n" +
+	"frontendId\x12\x18\n" +
+	"\aaddress\x18\x06 \x01(\tR\aaddress\x12\x1a\n" +
+	"\blocation\x18\a \x01(\tR\blocation\x12 \n" +
+	"\vlastLoginIP\x18\b \x01(\tR\vlastLoginIP\x12\x10\n" +
+	"\x03sex\x18\t \x01(\x05R\x03sex\x12\x14\n" +
+	"\x05score\x18\n" +
+	" \x01(\x05R\x05score\x12\x1e\n" +
+	"\n" +
+	"spreaderID\x18\v \x01(\tR\n" +
+	"spreaderID\x12\"\n" +
+	"\fprohibitGame\x18\f \x01(\bR\fprohibitGame\x12\x16\n" +
+	"\x06roomID\x18\r \x01(\tR\x06roomID\"o\n" +
+	"\x0fGameMessagePush\x12\x1e\n" +
+	"\n" +
+	"pushRouter\x18\x01 \x01(\tR\n" +
+	"pushRouter\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\x05R\x04type\x12(\n" +
+	"\x04data\x18\x03 \x01(\v2\x14.GameMessagePushDataR\x04data\"\xdb\x02\n" +
+	"\x13GameMessagePushData\x12\x1e\n" +
+	"\n" +
+	"gameStatus\x18\x01 \x01(\x05R\n" +
+	"gameStatus\x12\x12\n" +
+	"\x04tick\x18\x02 \x01(\x05R\x04tick\x12$\n" +
+	"\rbankerChairID\x18\x03 \x01(\x05R\rbankerChairID\x12\x1c\n" +
+	"\tcurBureau\x18\x04 \x01(\x05R\tcurBureau\x12\x18\n" +
+	"\achairID\x18\x05 \x01(\x05R\achairID\x12\x14\n" +
+	"\x05score\x18\x06 \x01(\x05R\x05score\x12\x1e\n" +
+	"\n" +
+	"chairScore\x18\a \x01(\x05R\n" +
+	"chairScore\x12\x16\n" +
+	"\x06scores\x18\b \x01(\x05R\x06scores\x12\x12\n" +
+	"\x04type\x18\t \x01(\x05R\x04type\x12\x14\n" +
+	"\x05round\x18\n" +
+	" \x01(\x05R\x05round\x12\x1e\n" +
+	"\n" +
+	"curChairID\x18\v \x01(\x05R\n" +
+	"curChairID\x12\x1a\n" +
+	"\bcurScore\x18\f \x01(\x05R\bcurScoreB\x0eZ\fcommon/pb;pbb\x06proto3"
+
+var (
+	file_client_proto_rawDescOnce sync.Once
+	file_client_proto_rawDescData []byte
+)
+
+func file_client_proto_rawDescGZIP() []byte {
+	file_client_proto_rawDescOnce.Do(func() {
+		file_client_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_client_proto_rawDesc), len(file_client_proto_rawDesc)))
+	})
+	return file_client_proto_rawDescData
+}
+
+var file_client_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_client_proto_goTypes = []any{
+	(*EntryReq)(nil),             // 0: EntryReq
+	(*EntryUserInfo)(nil),        // 1: EntryUserInfo
+	(*UpdateUserAddressReq)(nil), // 2: UpdateUserAddressReq
+	(*CreateRoomReq)(nil),        // 3: CreateRoomReq
+	(*GameRule)(nil),             // 4: GameRule
+	(*JoinRoomReq)(nil),          // 5: JoinRoomReq
+	(*RoomMessageReq)(nil),       // 6: RoomMessageReq
+	(*RoomMessageData)(nil),      // 7: RoomMessageData
+	(*UpdateUserInfoPush)(nil),   // 8: UpdateUserInfoPush
+	(*SelfEntryRoomPush)(nil),    // 9: SelfEntryRoomPush
+	(*RoomMessagePush)(nil),      // 10: RoomMessagePush
+	(*RoomMessagePushData)(nil),  // 11: RoomMessagePushData
+	(*RoomUser)(nil),             // 12: RoomUser
+	(*RoomUserInfo)(nil),         // 13: RoomUserInfo
+	(*GameMessagePush)(nil),      // 14: GameMessagePush
+	(*GameMessagePushData)(nil),  // 15: GameMessagePushData
+}
+var file_client_proto_depIdxs = []int32{
+	1,  // 0: EntryReq.userInfo:type_name -> EntryUserInfo
+	4,  // 1: CreateRoomReq.gameRule:type_name -> GameRule
+	7,  // 2: RoomMessageReq.data:type_name -> RoomMessageData
+	11, // 3: RoomMessagePush.data:type_name -> RoomMessagePushData
+	12, // 4: RoomMessagePushData.roomUserInfo:type_name -> RoomUser
+	13, // 5: RoomUser.userInfo:type_name -> RoomUserInfo
+	15, // 6: GameMessagePush.data:type_name -> GameMessagePushData
+	7,  // [7:7] is the sub-list for method output_type
+	7,  // [7:7] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_client_proto_init() }
+func file_client_proto_init() {
+	if File_client_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_client_proto_rawDesc), len(file_client_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   16,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_client_proto_goTypes,
+		DependencyIndexes: file_client_proto_depIdxs,
+		MessageInfos:      file_client_proto_msgTypes,
+	}.Build()
+	File_client_proto = out.File
+	file_client_proto_goTypes = nil
+	file_client_proto_depIdxs = nil
+}
@@ -0,0 +1,18 @@
+package pb
+
+import "framework/serializer"
+
+// Register 注册客户端请求和push的protobuf消息类型，connector和使用protobuf的客户端启动时调用
+// 消息定义在 common/api/client.proto，没有注册的路由和push仍然是json
+func Register() {
+	serializer.RegisterClientMessage("connector.entryHandler.entry", &EntryReq{})
+	serializer.RegisterClientMessage("hall.userHandler.updateUserAddress", &UpdateUserAddressReq{})
+	serializer.RegisterClientMessage("game.unionHandler.createRoom", &CreateRoomReq{})
+	serializer.RegisterClientMessage("game.unionHandler.joinRoom", &JoinRoomReq{})
+	serializer.RegisterClientMessage("game.gameHandler.roomMessageNotify", &RoomMessageReq{})
+
+	serializer.RegisterPushMessage("UpdateUserInfoPush", &UpdateUserInfoPush{})
+	serializer.RegisterPushMessage("SelfEntryRoomPush", &SelfEntryRoomPush{})
+	serializer.RegisterPushMessage("RoomMessagePush", &RoomMessagePush{})
+	serializer.RegisterPushMessage("GameMessagePush", &GameMessagePush{})
+}
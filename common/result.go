@@ -30,6 +30,7 @@ func Fail(ctx *gin.Context, err *err.Error) {
 func F(err *err.Error) Result {
 	return Result{
 		Code: err.Code,
+		Msg:  err.Error(),
 	}
 }
 
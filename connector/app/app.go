@@ -3,10 +3,13 @@ package app
 import (
 	"common/config"
 	"common/logs"
+	"common/pb"
 	"connector/route"
 	"context"
+	"core/dao"
 	"core/repo"
 	"framework/connector"
+	"framework/net"
 	"os"
 	"os/signal"
 	"syscall"
@@ -15,23 +18,32 @@ import (
 	"go.uber.org/zap"
 )
 
+const slowHandler = 500 * time.Millisecond
+
 // Run 启动各种服务
 func Run(ctx context.Context, serverId string) error {
 	// 初始化日志服务
 	logs.InitLogger(&config.Conf.Log)
 	zap.L().Info("初始化日志...")
 
-	exit := func() {}
+	c := connector.Default()
 	go func() {
-		c := connector.Default()
-		exit = c.Close
 		manager := repo.New()
 		c.RegisterHandler(route.Register(manager))
+		// 客户端握手时选择protobuf后按 common/api/client.proto 编解码
+		pb.Register()
+		// entry负责登录，不能加 net.Auth
+		c.Use(net.Logging(), net.Recover(), net.Timing(slowHandler))
+		for path, validate := range route.Validators() {
+			c.UseRoute(path, validate)
+		}
+		// 跨connector的单点登录
+		c.SetPresence(dao.NewPresenceDao(manager))
 		c.Run(serverId)
 	}()
 
 	stop := func() {
-		exit()
+		c.Close()
 		zap.L().Info("stop server")
 		time.Sleep(1 * time.Second)
 	}
@@ -41,13 +53,24 @@ func Run(ctx context.Context, serverId string) error {
 	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT, syscall.SIGHUP)
 	for {
 		select {
+		case <-c.Done():
+			// 管理接口触发了drain
+			stop()
+			zap.L().Info("stop after drain")
+			return nil
 		case <-ctx.Done():
 			stop()
 			zap.L().Info("ctx done")
 			return nil
 		case sig := <-ch:
 			switch sig {
-			case syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT:
+			case syscall.SIGTERM:
+				// 滚动发布，迁移客户端后退出
+				c.Drain()
+				stop()
+				zap.L().Info("stop by " + sig.String())
+				return nil
+			case syscall.SIGINT, syscall.SIGQUIT:
 				stop()
 				zap.L().Info("stop by " + sig.String())
 				return nil
@@ -9,7 +9,6 @@ import (
 	"context"
 	"core/repo"
 	"core/service"
-	"encoding/json"
 	"fmt"
 	"framework/game"
 	"framework/net"
@@ -21,31 +20,31 @@ type EntryHandler struct {
 	userService *service.UserService
 }
 
-func (h *EntryHandler) Entry(session *net.Session, body []byte) (any, error) {
+func (h *EntryHandler) Entry(ctx context.Context, session *net.Session, body []byte) (any, error) {
 	zap.L().Info("==============Entry Start=====================")
 	zap.L().Info("entry request params: " + string(body))
 	zap.L().Info("==============Entry End=====================")
-	var req request.EntryReq
-	err := json.Unmarshal(body, &req)
-	if err != nil {
-		return common.F(biz.RequestDataError), nil
+	req, ok := net.Request[request.EntryReq](ctx)
+	if !ok {
+		return nil, biz.RequestDataError
 	}
 	// 校验token
 	uid, err := jwts.ParseToken(req.Token, config.Conf.Jwt.Secret)
 	if err != nil {
 		zap.L().Error("parse token err: ", zap.Error(err))
-		return common.F(biz.TokenInfoError), nil
+		return nil, biz.TokenInfoError
 	}
 	// 根据uid 去mongo中查询用户 如果用户不存在 生成一个用户
-	user, err := h.userService.FindAndSaveUserByUid(context.TODO(), uid, req.UserInfo)
+	user, err := h.userService.FindAndSaveUserByUid(ctx, uid, req.UserInfo)
 	if err != nil {
-		return common.F(biz.SqlError), nil
+		return nil, biz.SqlError
 	}
 	fmt.Printf("session = %v\n", session)
-	session.Uid = uid
+	session.SetUid(uid)
 	return common.S(map[string]any{
-		"userInfo": user,
-		"config":   game.Conf.GetFrontGameConfig(),
+		"userInfo":    user,
+		"config":      game.Conf.GetFrontGameConfig(),
+		"resumeToken": session.ResumeToken(), // 断线重连时在握手中带上
 	}), nil
 }
 
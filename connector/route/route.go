@@ -1,7 +1,9 @@
 package route
 
 import (
+	"common/biz"
 	"connector/handler"
+	"connector/models/request"
 	"core/repo"
 	"framework/net"
 )
@@ -13,3 +15,10 @@ func Register(r *repo.Manager) net.LogicHandler {
 
 	return handlers
 }
+
+// Validators 每个路由的请求参数，handler通过 net.Request 获取解析后的参数
+func Validators() map[string]net.Middleware {
+	return map[string]net.Middleware{
+		"entryHandler.entry": net.Validate[request.EntryReq](biz.RequestDataError),
+	}
+}
@@ -0,0 +1,61 @@
+package dao
+
+import (
+	"context"
+	"core/repo"
+	"time"
+)
+
+const (
+	ChannelRedisKey = "channel"
+	channelExpire   = 24 * time.Hour // 节点异常退出时没有Destroy的频道过期删除
+)
+
+// ChannelDao 多个节点共享的频道成员，每个频道是一个redis set
+type ChannelDao struct {
+	repo *repo.Manager
+}
+
+func NewChannelDao(repo *repo.Manager) *ChannelDao {
+	return &ChannelDao{
+		repo: repo,
+	}
+}
+
+func (c *ChannelDao) Add(ctx context.Context, name string, uids ...string) error {
+	if len(uids) == 0 {
+		return nil
+	}
+	members := make([]any, len(uids))
+	for i, uid := range uids {
+		members[i] = uid
+	}
+	pipe := c.repo.Redis.Client.TxPipeline()
+	pipe.SAdd(ctx, c.key(name), members...)
+	pipe.Expire(ctx, c.key(name), channelExpire)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (c *ChannelDao) Remove(ctx context.Context, name string, uids ...string) error {
+	if len(uids) == 0 {
+		return nil
+	}
+	members := make([]any, len(uids))
+	for i, uid := range uids {
+		members[i] = uid
+	}
+	return c.repo.Redis.Client.SRem(ctx, c.key(name), members...).Err()
+}
+
+func (c *ChannelDao) Members(ctx context.Context, name string) ([]string, error) {
+	return c.repo.Redis.Client.SMembers(ctx, c.key(name)).Result()
+}
+
+func (c *ChannelDao) Destroy(ctx context.Context, name string) error {
+	return c.repo.Redis.Client.Del(ctx, c.key(name)).Err()
+}
+
+func (c *ChannelDao) key(name string) string {
+	return Prefix + ":" + ChannelRedisKey + ":" + name
+}
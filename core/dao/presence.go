@@ -0,0 +1,108 @@
+package dao
+
+import (
+	"context"
+	"core/repo"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	PresenceRedisKey = "presence"
+	presenceExpire   = 24 * time.Hour
+)
+
+// 只有记录仍然是当前连接时才删除
+var presenceLogoutScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+type presenceRecord struct {
+	ServerId string `json:"serverId"`
+	Cid      string `json:"cid"`
+}
+
+// PresenceDao 多个connector共享的在线记录
+type PresenceDao struct {
+	repo *repo.Manager
+}
+
+func NewPresenceDao(repo *repo.Manager) *PresenceDao {
+	return &PresenceDao{
+		repo: repo,
+	}
+}
+
+func (p *PresenceDao) Login(ctx context.Context, uid, serverId, cid string) (string, string, error) {
+	value, _ := json.Marshal(presenceRecord{ServerId: serverId, Cid: cid})
+	prev, err := p.repo.Redis.Client.SetArgs(ctx, p.key(uid), value, redis.SetArgs{
+		TTL: presenceExpire,
+		Get: true,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", "", nil
+	}
+	if err != nil {
+		return "", "", err
+	}
+	var record presenceRecord
+	if err := json.Unmarshal([]byte(prev), &record); err != nil {
+		return "", "", err
+	}
+	return record.ServerId, record.Cid, nil
+}
+
+func (p *PresenceDao) Logout(ctx context.Context, uid, serverId, cid string) error {
+	value, _ := json.Marshal(presenceRecord{ServerId: serverId, Cid: cid})
+	return presenceLogoutScript.Run(ctx, p.repo.Redis.Client, []string{p.key(uid)}, string(value)).Err()
+}
+
+func (p *PresenceDao) Get(ctx context.Context, uid string) (string, string, error) {
+	value, err := p.repo.Redis.Client.Get(ctx, p.key(uid)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", "", nil
+	}
+	if err != nil {
+		return "", "", err
+	}
+	var record presenceRecord
+	if err := json.Unmarshal([]byte(value), &record); err != nil {
+		return "", "", err
+	}
+	return record.ServerId, record.Cid, nil
+}
+
+// Locate 集群模式下不同的key可能不在同一个slot，使用pipeline代替MGET
+func (p *PresenceDao) Locate(ctx context.Context, uids []string) (map[string]string, error) {
+	pipe := p.repo.Redis.Client.Pipeline()
+	cmds := make([]*redis.StringCmd, len(uids))
+	for i, uid := range uids {
+		cmds[i] = pipe.Get(ctx, p.key(uid))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+	located := make(map[string]string, len(uids))
+	for i, cmd := range cmds {
+		value, err := cmd.Result()
+		if err != nil {
+			continue
+		}
+		var record presenceRecord
+		if err := json.Unmarshal([]byte(value), &record); err != nil {
+			continue
+		}
+		located[uids[i]] = record.ServerId
+	}
+	return located, nil
+}
+
+func (p *PresenceDao) key(uid string) string {
+	return Prefix + ":" + PresenceRedisKey + ":" + uid
+}
@@ -2,4 +2,7 @@ module core
 
 go 1.24.3
 
-require go.mongodb.org/mongo-driver v1.17.6
+require (
+	github.com/redis/go-redis/v9 v9.17.2
+	go.mongodb.org/mongo-driver v1.17.6
+)
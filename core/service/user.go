@@ -37,7 +37,7 @@ func (s *UserService) FindAndSaveUserByUid(ctx context.Context, uid string, info
 		user.Sex = info.Sex // 0 男 1 女
 		user.CreateTime = time.Now().UnixMilli()
 		user.LastLoginTime = time.Now().UnixMilli()
-		err = s.userDao.Insert(context.TODO(), user)
+		err = s.userDao.Insert(ctx, user)
 		if err != nil {
 			zap.L().Error("[UserService] FindAndSaveUserByUid insert user err: ", zap.Error(err))
 			return nil, err
@@ -56,14 +56,14 @@ func (s *UserService) FindUserByUid(ctx context.Context, uid string) (*entity.Us
 	return user, nil
 }
 
-func (s *UserService) UpdateUserAddress(uid string, req hall.UpdateUserAddressReq) error {
+func (s *UserService) UpdateUserAddress(ctx context.Context, uid string, req hall.UpdateUserAddressReq) error {
 	user := &entity.User{
 		Uid:      uid,
 		Address:  req.Address,
 		Location: req.Location,
 	}
 
-	err := s.userDao.UpdateUserAddress(context.TODO(), user)
+	err := s.userDao.UpdateUserAddress(ctx, user)
 	if err != nil {
 		zap.L().Error("userDao.UpdateUserAddressByUid err: ", zap.Error(err))
 		return err
@@ -0,0 +1,389 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	errs "framework/err"
+	"framework/protocol"
+	"framework/serializer"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrClosed         = errors.New("client closed")
+	ErrRequestTimeout = errors.New("request timeout")
+	ErrHandshake      = errors.New("handshake failed")
+)
+
+const (
+	defaultRequestTimeout = 10 * time.Second
+	writeWait             = 10 * time.Second
+)
+
+// PushHandler 推送消息的处理函数，data为json格式的消息体
+type PushHandler func(data []byte)
+
+// 响应中没有路由，按照请求的路由转换消息体
+type pendingRequest struct {
+	route string
+	ch    chan *protocol.Message
+}
+
+// Client pomelo风格协议的客户端，用于机器人、测试和压测脚本
+type Client struct {
+	sync.RWMutex
+	addr           string
+	conn           *websocket.Conn
+	writeLock      sync.Mutex
+	sys            protocol.Sys // 握手时请求的参数
+	serializer     serializer.Serializer
+	dict           *protocol.Dictionary
+	heartbeat      time.Duration
+	resumed        bool
+	RequestTimeout time.Duration
+	reqId          atomic.Uint64
+	pending        map[uint]*pendingRequest
+	pushHandlers   map[string][]PushHandler
+	kickHandler    func(reason protocol.KickReason)
+	closeOnce      sync.Once
+	closeChan      chan struct{}
+}
+
+func NewClient(addr string) *Client {
+	return &Client{
+		addr: addr,
+		sys: protocol.Sys{
+			Type:       "go-client",
+			Version:    "1.0.0",
+			Serializer: serializer.JSON,
+		},
+		serializer:     serializer.Default(),
+		RequestTimeout: defaultRequestTimeout,
+		pending:        make(map[uint]*pendingRequest),
+		pushHandlers:   make(map[string][]PushHandler),
+		closeChan:      make(chan struct{}),
+	}
+}
+
+// SetSerializer 握手时请求的序列化方式，以服务端回复的为准
+func (c *Client) SetSerializer(name string) {
+	c.sys.Serializer = name
+}
+
+// SetCompress 握手时请求开启下行压缩
+func (c *Client) SetCompress(compress bool) {
+	c.sys.Compress = compress
+}
+
+// SetResumeToken 设置登录时返回的恢复令牌，断线重连时在握手中带上
+func (c *Client) SetResumeToken(token string) {
+	c.sys.ResumeToken = token
+}
+
+// Resumed 握手时是否恢复了断线前的session，没有恢复时需要重新登录
+func (c *Client) Resumed() bool {
+	return c.resumed
+}
+
+// ResumeToken 恢复成功后服务端下发的新令牌
+func (c *Client) ResumeToken() string {
+	return c.sys.ResumeToken
+}
+
+// Connect 建立连接并完成 Handshake/HandshakeAck
+func (c *Client) Connect() error {
+	conn, _, err := websocket.DefaultDialer.Dial(c.addr, nil)
+	if err != nil {
+		return err
+	}
+	c.conn = conn
+	if err := c.handshake(); err != nil {
+		_ = conn.Close()
+		return err
+	}
+	go c.readMessage()
+	if c.heartbeat > 0 {
+		go c.heartbeatLoop()
+	}
+	return nil
+}
+
+func (c *Client) handshake() error {
+	data, _ := json.Marshal(protocol.HandshakeBody{Sys: c.sys})
+	if err := c.send(protocol.Handshake, data); err != nil {
+		return err
+	}
+	_ = c.conn.SetReadDeadline(time.Now().Add(c.RequestTimeout))
+	_, buf, err := c.conn.ReadMessage()
+	if err != nil {
+		return err
+	}
+	_ = c.conn.SetReadDeadline(time.Time{})
+	if len(buf) < protocol.HeaderLen || protocol.PackageType(buf[0]) != protocol.Handshake {
+		return ErrHandshake
+	}
+	var res protocol.HandshakeResponse
+	if err := json.Unmarshal(buf[protocol.HeaderLen:], &res); err != nil {
+		return err
+	}
+	if res.Code != 200 {
+		return ErrHandshake
+	}
+	if sz, ok := serializer.Get(res.Sys.Serializer); ok {
+		c.serializer = sz
+	}
+	c.dict = protocol.NewDictionary(res.Sys.Dict)
+	c.heartbeat = time.Duration(res.Sys.Heartbeat) * time.Second
+	c.resumed = res.Sys.Resumed
+	if res.Sys.Resumed {
+		c.sys.ResumeToken = res.Sys.ResumeToken
+	}
+	return c.send(protocol.HandshakeAck, nil)
+}
+
+// Request 发送请求并等待 Message.ID 相同的响应，返回json格式的消息体
+func (c *Client) Request(route string, data any) ([]byte, error) {
+	id := uint(c.reqId.Add(1))
+	ch := make(chan *protocol.Message, 1)
+	c.Lock()
+	c.pending[id] = &pendingRequest{
+		route: route,
+		ch:    ch,
+	}
+	c.Unlock()
+	defer func() {
+		c.Lock()
+		delete(c.pending, id)
+		c.Unlock()
+	}()
+
+	if err := c.sendMessage(protocol.Request, id, route, data); err != nil {
+		return nil, err
+	}
+	timer := time.NewTimer(c.RequestTimeout)
+	defer timer.Stop()
+	select {
+	case msg := <-ch:
+		bodyRoute := route
+		if msg.Error {
+			bodyRoute = "" // 错误响应的消息体不按路由转换
+		}
+		body, err := c.decodeBody(bodyRoute, msg.Data)
+		if err != nil {
+			return nil, err
+		}
+		if msg.Error {
+			var e errs.Body
+			if err := json.Unmarshal(body, &e); err != nil {
+				return nil, err
+			}
+			return nil, errs.NewError(e.Code, errors.New(e.Msg))
+		}
+		return body, nil
+	case <-timer.C:
+		return nil, ErrRequestTimeout
+	case <-c.closeChan:
+		return nil, ErrClosed
+	}
+}
+
+// Notify 发送通知，不需要响应
+func (c *Client) Notify(route string, data any) error {
+	return c.sendMessage(protocol.Notify, 0, route, data)
+}
+
+// On 订阅推送，按消息体中的 pushRouter 分发，没有 pushRouter 时按消息路由分发
+func (c *Client) On(pushRouter string, handler PushHandler) {
+	c.Lock()
+	defer c.Unlock()
+	c.pushHandlers[pushRouter] = append(c.pushHandlers[pushRouter], handler)
+}
+
+// OnKick 被服务端踢出时回调
+func (c *Client) OnKick(handler func(reason protocol.KickReason)) {
+	c.Lock()
+	defer c.Unlock()
+	c.kickHandler = handler
+}
+
+func (c *Client) Close() {
+	c.closeOnce.Do(func() {
+		close(c.closeChan)
+		if c.conn != nil {
+			_ = c.conn.Close()
+		}
+	})
+}
+
+// Done 连接关闭时返回
+func (c *Client) Done() <-chan struct{} {
+	return c.closeChan
+}
+
+func (c *Client) sendMessage(msgType protocol.MessageType, id uint, route string, data any) error {
+	body, err := c.encodeBody(route, data)
+	if err != nil {
+		return err
+	}
+	msg := &protocol.Message{
+		Type:  msgType,
+		ID:    id,
+		Route: route,
+		Data:  body,
+	}
+	buf, err := protocol.MessageEncode(msg, c.dict)
+	if err != nil {
+		return err
+	}
+	return c.send(protocol.Data, buf)
+}
+
+func (c *Client) send(packageType protocol.PackageType, body []byte) error {
+	buf, err := protocol.Encode(packageType, body)
+	if err != nil {
+		return err
+	}
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+	select {
+	case <-c.closeChan:
+		return ErrClosed
+	default:
+	}
+	_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	return c.conn.WriteMessage(websocket.BinaryMessage, buf)
+}
+
+func (c *Client) readMessage() {
+	defer c.Close()
+	decoder := protocol.NewDecoder()
+	for {
+		_, buf, err := c.conn.ReadMessage()
+		if err != nil {
+			select {
+			case <-c.closeChan:
+			default:
+				zap.L().Error("client read message err: ", zap.Error(err))
+			}
+			return
+		}
+		// 服务端写队列积压时会把多个数据包合并在同一帧中
+		packets, err := decoder.Decode(buf)
+		if err != nil {
+			zap.L().Error("client decode frame err: ", zap.Error(err))
+			return
+		}
+		for _, v := range packets {
+			if !c.handlePacket(v) {
+				return
+			}
+		}
+	}
+}
+
+// handlePacket 处理一个数据包，返回false表示被踢出
+func (c *Client) handlePacket(buf []byte) bool {
+	packet, err := protocol.Decode(buf, c.dict)
+	if err != nil {
+		zap.L().Error("client decode message err: ", zap.Error(err))
+		return true
+	}
+	switch packet.Type {
+	case protocol.Data:
+		c.dispatch(packet.MessageBody())
+	case protocol.Kick:
+		var reason protocol.KickReason
+		_ = json.Unmarshal(buf[protocol.HeaderLen:], &reason)
+		zap.L().Sugar().Infof("client kicked by server, reason=%v", reason)
+		c.RLock()
+		handler := c.kickHandler
+		c.RUnlock()
+		if handler != nil {
+			handler(reason)
+		}
+		return false
+	}
+	return true
+}
+
+func (c *Client) dispatch(msg *protocol.Message) {
+	switch msg.Type {
+	case protocol.Response:
+		c.RLock()
+		req, ok := c.pending[msg.ID]
+		c.RUnlock()
+		if ok {
+			req.ch <- msg
+		}
+	case protocol.Push:
+		body, err := c.decodeBody(msg.Route, msg.Data)
+		if err != nil {
+			zap.L().Error("client decode push err: ", zap.Error(err))
+			return
+		}
+		var push struct {
+			PushRouter string `json:"pushRouter"`
+		}
+		_ = json.Unmarshal(body, &push)
+		pushRouter := push.PushRouter
+		if pushRouter == "" {
+			pushRouter = msg.Route
+		}
+		c.RLock()
+		handlers := c.pushHandlers[pushRouter]
+		c.RUnlock()
+		for _, handler := range handlers {
+			handler(body)
+		}
+	}
+}
+
+// 将请求转换为协商的格式，protobuf按路由注册的消息转换
+func (c *Client) encodeBody(route string, data any) ([]byte, error) {
+	codec, ok := c.serializer.(serializer.RouteCodec)
+	if !ok && c.serializer.Name() != serializer.JSON {
+		return c.serializer.Marshal(data)
+	}
+	body, err := json.Marshal(data)
+	if err != nil || !ok {
+		return body, err
+	}
+	return codec.EncodeRoute(serializer.ClientMessage, route, body)
+}
+
+// 将服务端的消息体转换为json
+func (c *Client) decodeBody(route string, data []byte) ([]byte, error) {
+	if len(data) == 0 || c.serializer.Name() == serializer.JSON {
+		return data, nil
+	}
+	if codec, ok := c.serializer.(serializer.RouteCodec); ok {
+		return codec.DecodeRoute(serializer.ServerMessage, route, data)
+	}
+	var raw json.RawMessage
+	if err := c.serializer.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+func (c *Client) heartbeatLoop() {
+	ticker := time.NewTicker(c.heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.send(protocol.Heartbeat, nil); err != nil {
+				zap.L().Error("client send heartbeat err: ", zap.Error(err))
+				c.Close()
+				return
+			}
+		case <-c.closeChan:
+			return
+		}
+	}
+}
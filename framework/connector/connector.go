@@ -14,10 +14,18 @@ type Connector struct {
 	wsManager *net.Manager
 	handlers  net.LogicHandler
 	remoteCli remote.Client
+	done      chan struct{}
+	presence  remote.Presence
+	// 中间件在Run时交给Manager
+	middlewares      []net.Middleware
+	routeMiddlewares map[string][]net.Middleware
 }
 
 func Default() *Connector {
-	return &Connector{}
+	return &Connector{
+		done:             make(chan struct{}),
+		routeMiddlewares: make(map[string][]net.Middleware),
+	}
 }
 
 func (c *Connector) Run(serverId string) {
@@ -29,6 +37,16 @@ func (c *Connector) Run(serverId string) {
 		c.remoteCli = remote.NewNatsClient(serverId, c.wsManager.RemoteReadChan)
 		_ = c.remoteCli.Run()
 		c.wsManager.RemoteCli = c.remoteCli
+		c.wsManager.SetPresence(c.presence)
+		c.wsManager.Use(c.middlewares...)
+		for route, middlewares := range c.routeMiddlewares {
+			c.wsManager.UseRoute(route, middlewares...)
+		}
+		// 管理接口触发的drain完成后通知进程退出
+		go func() {
+			<-c.wsManager.Done()
+			close(c.done)
+		}()
 		c.Serve(serverId)
 	}
 }
@@ -50,6 +68,36 @@ func (c *Connector) Close() {
 	}
 }
 
+// SetPresence 设置多个connector共享的在线记录，用于跨connector的单点登录，需要在Run之前调用
+func (c *Connector) SetPresence(presence remote.Presence) {
+	c.presence = presence
+}
+
+// Drain 通知客户端迁移到其他connector，等待进行中的请求回复后断开所有连接
+func (c *Connector) Drain() {
+	if c.wsManager != nil {
+		c.wsManager.Drain()
+	}
+	if c.remoteCli != nil {
+		_ = c.remoteCli.Close()
+	}
+}
+
+// Done drain完成后关闭
+func (c *Connector) Done() <-chan struct{} {
+	return c.done
+}
+
+// Use 注册全局中间件，需要在Run之前调用
+func (c *Connector) Use(middlewares ...net.Middleware) {
+	c.middlewares = append(c.middlewares, middlewares...)
+}
+
+// UseRoute 注册路由中间件，例如 entryHandler.entry
+func (c *Connector) UseRoute(route string, middlewares ...net.Middleware) {
+	c.routeMiddlewares[route] = append(c.routeMiddlewares[route], middlewares...)
+}
+
 func (c *Connector) RegisterHandler(handlers net.LogicHandler) {
 	c.handlers = handlers
 }
@@ -0,0 +1,15 @@
+package err
+
+import "errors"
+
+// 框架层的错误码，与业务错误码（common/biz）区分开
+var (
+	InternalError    = NewError(500, errors.New("服务器内部错误"))
+	TooFrequent      = NewError(502, errors.New("请求过于频繁"))
+	HandleTimeout    = NewError(504, errors.New("请求处理超时"))
+	RPCRouteNotFound = NewError(404, errors.New("rpc路由不存在"))
+	AlreadyLoggedIn  = NewError(409, errors.New("连接已经登录，切换用户需要重新连接"))
+	RPCUnavailable   = NewError(503, errors.New("rpc目标节点不可用"))
+	RPCTimeout       = NewError(506, errors.New("rpc请求超时"))
+	ServerDraining   = NewError(507, errors.New("服务器正在下线，请迁移到其他服务器"))
+)
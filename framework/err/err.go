@@ -7,11 +7,20 @@ import (
 	"google.golang.org/grpc/status"
 )
 
+// DefaultCode 非 *Error 类型的错误返回给客户端时使用的错误码
+const DefaultCode = 1
+
 type Error struct {
 	Code int
 	Err  error
 }
 
+// Body 错误响应的消息体，与正常响应的 {code, msg} 结构保持一致
+type Body struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+}
+
 func (e *Error) Error() string {
 	return e.Err.Error()
 }
@@ -23,6 +32,22 @@ func NewError(code int, err error) *Error {
 	}
 }
 
+func (e *Error) Body() Body {
+	return Body{
+		Code: e.Code,
+		Msg:  e.Error(),
+	}
+}
+
+// Wrap 将任意错误转换为 *Error
+func Wrap(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return NewError(DefaultCode, err)
+}
+
 func GrpcError(err *Error) error {
 	return status.Error(codes.Code(err.Code), err.Error())
 }
@@ -40,11 +40,47 @@ type ServersConfig struct {
 }
 
 type ConnectorConfig struct {
-	ID         string `json:"id"`
-	Host       string `json:"host"`
-	ClientPort int    `json:"clientPort"`
-	Frontend   bool   `json:"frontend"`
-	ServerType string `json:"serverType"`
+	ID                  string           `json:"id"`
+	Host                string           `json:"host"`
+	ClientPort          int              `json:"clientPort"`
+	TcpPort             int              `json:"tcpPort"` // 原生客户端的tcp端口，0不监听
+	Frontend            bool             `json:"frontend"`
+	ServerType          string           `json:"serverType"`
+	HeartTime           int              `json:"heartTime"`         // 心跳间隔（秒），握手时下发给客户端
+	HeartMaxMissed      int              `json:"heartMaxMissed"`    // 连续多少个心跳周期没有收到数据包就断开连接
+	HandshakeTimeout    int              `json:"handshakeTimeout"`  // 建立连接后多少秒内没有完成握手就断开连接
+	CompressThreshold   int              `json:"compressThreshold"` // 下行消息体超过该字节数时压缩，0不压缩
+	CertFile            string           `json:"certFile"`          // 证书和私钥都配置时开启tls，文件变化后自动重新加载
+	KeyFile             string           `json:"keyFile"`
+	AllowOrigins        []string         `json:"allowOrigins"` // websocket允许的Origin，为空时不校验
+	RateLimit           *RateLimitConfig `json:"rateLimit"`
+	WriteQueueSize      int              `json:"writeQueueSize"`      // 每个连接写队列的长度，默认1024
+	SlowPolicy          string           `json:"slowPolicy"`          // 写队列满时的处理策略 dropOldest|coalesce|disconnect，默认dropOldest
+	MaxConnections      int              `json:"maxConnections"`      // 最大连接数，0不限制
+	MaxConnectionsPerIP int              `json:"maxConnectionsPerIP"` // 单个ip的最大连接数，0不限制
+	DrainTimeout        int              `json:"drainTimeout"`        // drain时等待请求回复的最长时间（秒），默认10
+	HandleTimeOut       int              `json:"handleTimeOut"`       // connector本地handler的ctx超时时间（秒），默认5
+	AdminAddr           string           `json:"adminAddr"`           // 管理接口监听地址，例如127.0.0.1:12100，为空不监听
+	ResumeGrace         int              `json:"resumeGrace"`         // 断线后保留session的时间（秒），期间重连可以恢复，0不保留
+	MaxPacketSize       int              `json:"maxPacketSize"`       // 客户端数据包（包含4字节包头）的最大长度，websocket和tcp相同，默认1024
+}
+
+// RateLimitConfig 客户端数据包限流，按连接和路由分别限制
+// 路由中带有"."，viper会把map的key解析成多层，所以路由限流使用数组配置
+type RateLimitConfig struct {
+	Rate          float64             `json:"rate"`          // 每个连接每秒允许的数据包数，0不限制
+	Burst         int                 `json:"burst"`         // 允许的突发数量
+	MaxViolations int                 `json:"maxViolations"` // 一分钟内超限次数达到该值时踢出，0不踢出
+	Routes        []*RouteLimitConfig `json:"routes"`
+}
+
+// RouteLimitConfig 同一个路由可以配置多条，例如整个路由一条、聊天类型单独一条
+type RouteLimitConfig struct {
+	Route  string   `json:"route"`  // 完整路由，例如 game.gameHandler.roomMessageNotify
+	Field  string   `json:"field"`  // 按消息体中的字段区分子类型，例如 type，为空时限制整个路由
+	Values []string `json:"values"` // field的取值，数字也写成字符串，例如 ["307"]
+	Rate   float64  `json:"rate"`
+	Burst  int      `json:"burst"`
 }
 
 type NatsConfig struct {
@@ -162,6 +198,15 @@ func (c *Config) GetConnectorByServerType(serverType string) *ConnectorConfig {
 	return nil
 }
 
+func (c *Config) GetServer(serverId string) *ServersConfig {
+	for _, v := range c.ServersConf.Servers {
+		if v.ID == serverId {
+			return v
+		}
+	}
+	return nil
+}
+
 func (c *Config) GetFrontGameConfig() map[string]any {
 	result := make(map[string]any)
 	for k, v := range c.GameConfig {
@@ -0,0 +1,71 @@
+package net
+
+import (
+	"errors"
+	gonet "net"
+	"sync"
+)
+
+var (
+	ErrTooManyConnections      = errors.New("too many connections")
+	ErrTooManyConnectionsPerIP = errors.New("too many connections from this ip")
+)
+
+// admission 连接准入控制，限制总连接数和单个ip的连接数，0不限制
+type admission struct {
+	sync.Mutex
+	maxConns int
+	maxPerIP int
+	total    int
+	perIP    map[string]int
+}
+
+func newAdmission() *admission {
+	return &admission{
+		perIP: make(map[string]int),
+	}
+}
+
+func (a *admission) setLimit(maxConns, maxPerIP int) {
+	a.Lock()
+	defer a.Unlock()
+	a.maxConns = maxConns
+	a.maxPerIP = maxPerIP
+}
+
+// acquire 占用一个连接名额，连接断开时需要 release
+func (a *admission) acquire(ip string) error {
+	a.Lock()
+	defer a.Unlock()
+	if a.maxConns > 0 && a.total >= a.maxConns {
+		return ErrTooManyConnections
+	}
+	if a.maxPerIP > 0 && a.perIP[ip] >= a.maxPerIP {
+		return ErrTooManyConnectionsPerIP
+	}
+	a.total++
+	a.perIP[ip]++
+	return nil
+}
+
+func (a *admission) release(ip string) {
+	a.Lock()
+	defer a.Unlock()
+	if a.perIP[ip] <= 0 {
+		return
+	}
+	a.total--
+	a.perIP[ip]--
+	if a.perIP[ip] == 0 {
+		delete(a.perIP, ip)
+	}
+}
+
+// 去掉端口，只保留ip
+func remoteIP(addr string) string {
+	host, _, err := gonet.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
@@ -0,0 +1,49 @@
+package net
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+type ConnState int32
+
+// 连接状态只会向后流转
+const (
+	StateConnected     ConnState = iota // 建立连接，等待握手
+	StateHandshaked                     // 已回复握手，等待握手确认
+	StateAcked                          // 握手完成，可以收发数据
+	StateAuthenticated                  // 已经登录，session绑定了uid
+	StateClosing                        // 正在关闭
+)
+
+// connBase 各种连接共用的状态
+type connBase struct {
+	lastActive atomic.Int64 // 最后一次收到客户端数据包的时间
+	state      atomic.Int32
+	limiter    *RateLimiter
+	ip         string // 客户端ip，用于连接准入控制
+}
+
+func (b *connBase) Touch() {
+	b.lastActive.Store(time.Now().UnixNano())
+}
+
+func (b *connBase) LastActive() time.Time {
+	return time.Unix(0, b.lastActive.Load())
+}
+
+func (b *connBase) State() ConnState {
+	return ConnState(b.state.Load())
+}
+
+func (b *connBase) SetState(state ConnState) {
+	b.state.Store(int32(state))
+}
+
+func (b *connBase) Limiter() *RateLimiter {
+	return b.limiter
+}
+
+func (b *connBase) RemoteIP() string {
+	return b.ip
+}
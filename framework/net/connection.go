@@ -1,9 +1,18 @@
 package net
 
+import "time"
+
 type Connection interface {
 	Close()
 	SendMessage(buf []byte) error
+	SendAndClose(buf []byte) error
 	GetSession() *Session
+	Touch()
+	LastActive() time.Time
+	State() ConnState
+	SetState(state ConnState)
+	Limiter() *RateLimiter
+	RemoteIP() string
 }
 
 type MsgPack struct {
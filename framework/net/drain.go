@@ -0,0 +1,207 @@
+package net
+
+import (
+	"context"
+	"encoding/json"
+	"framework/game"
+	"framework/protocol"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultDrainTimeout = 10 * time.Second
+	MigrateRoute        = "migrate"
+)
+
+// MigrateData drain时推送给客户端的迁移消息，客户端收到后重连到指定的connector
+type MigrateData struct {
+	PushRouter string `json:"pushRouter"`
+	ServerId   string `json:"serverId"`
+	Host       string `json:"host"`
+	ClientPort int    `json:"clientPort"`
+	TcpPort    int    `json:"tcpPort"`
+}
+
+// inflight 转发给node节点还没有收到回复的请求数，drain时等待清零
+type inflight struct {
+	sync.Mutex
+	conns map[string]int
+	total int
+}
+
+func newInflight() *inflight {
+	return &inflight{
+		conns: make(map[string]int),
+	}
+}
+
+func (f *inflight) add(cid string) {
+	f.Lock()
+	defer f.Unlock()
+	f.conns[cid]++
+	f.total++
+}
+
+func (f *inflight) done(cid string) {
+	f.Lock()
+	defer f.Unlock()
+	if f.conns[cid] <= 0 {
+		return
+	}
+	f.total--
+	if f.conns[cid]--; f.conns[cid] == 0 {
+		delete(f.conns, cid)
+	}
+}
+
+// drop 连接断开后不会再收到回复
+func (f *inflight) drop(cid string) {
+	f.Lock()
+	defer f.Unlock()
+	f.total -= f.conns[cid]
+	delete(f.conns, cid)
+}
+
+func (f *inflight) count() int {
+	f.Lock()
+	defer f.Unlock()
+	return f.total
+}
+
+// wait 等待所有请求收到回复，超时返回false
+func (f *inflight) wait(timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for f.count() > 0 {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return true
+}
+
+func (m *Manager) Draining() bool {
+	return m.draining.Load()
+}
+
+// Done drain完成后关闭
+func (m *Manager) Done() <-chan struct{} {
+	return m.drained
+}
+
+// Drain 滚动发布时下线connector：停止接收新连接，通知客户端迁移到其他connector，
+// 等待已经转发给node节点的请求回复之后断开所有连接，只会执行一次
+func (m *Manager) Drain() {
+	if !m.draining.CompareAndSwap(false, true) {
+		<-m.drained
+		return
+	}
+	defer close(m.drained)
+	zap.L().Sugar().Infof("connector[%s] start draining", m.ServerId)
+	// 停止接收新连接
+	if m.server != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		_ = m.server.Shutdown(ctx)
+		cancel()
+	}
+	m.RLock()
+	listener := m.tcpListener
+	m.RUnlock()
+	if listener != nil {
+		_ = listener.Close()
+	}
+	// 通知客户端迁移
+	if target := m.migrateTarget(); target != nil {
+		m.broadcast(&protocol.Message{
+			Type:  protocol.Push,
+			Route: MigrateRoute,
+			Data:  target,
+		})
+	}
+	// 等待进行中的请求回复
+	if !m.inflight.wait(m.drainTimeout) {
+		zap.L().Sugar().Warnf("connector[%s] drain timeout, %d requests not responded", m.ServerId, m.inflight.count())
+	}
+	m.RLock()
+	clients := make([]Connection, 0, len(m.clients))
+	for _, v := range m.clients {
+		clients = append(clients, v)
+	}
+	m.RUnlock()
+	data, _ := json.Marshal(protocol.KickReason{Code: protocol.KickServer, Reason: "server maintenance"})
+	kick, err := protocol.Encode(protocol.Kick, data)
+	if err != nil {
+		zap.L().Error("encode kick packet err: ", zap.Error(err))
+		return
+	}
+	for _, v := range clients {
+		v.SetState(StateClosing)
+		_ = v.SendAndClose(kick)
+	}
+	// 等待写队列中的消息写出
+	deadline := time.Now().Add(writeWait)
+	for m.clientCount() > 0 && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	zap.L().Sugar().Infof("connector[%s] drained", m.ServerId)
+}
+
+// 选择同类型的其他connector作为迁移目标
+func (m *Manager) migrateTarget() []byte {
+	current := game.Conf.GetConnector(m.ServerId)
+	for _, v := range game.Conf.ServersConf.Connector {
+		if v.ID == m.ServerId || (current != nil && v.ServerType != current.ServerType) {
+			continue
+		}
+		data, _ := json.Marshal(MigrateData{
+			PushRouter: MigrateRoute,
+			ServerId:   v.ID,
+			Host:       v.Host,
+			ClientPort: v.ClientPort,
+			TcpPort:    v.TcpPort,
+		})
+		return data
+	}
+	zap.L().Sugar().Warnf("connector[%s] no migrate target", m.ServerId)
+	return nil
+}
+
+// 推送消息给所有连接
+func (m *Manager) broadcast(message *protocol.Message) {
+	m.RLock()
+	defer m.RUnlock()
+	for _, v := range m.clients {
+		res, err := m.encodeMessage(v.GetSession(), message)
+		if err != nil {
+			zap.L().Sugar().Errorf("broadcast encode err:%v", err)
+			continue
+		}
+		_ = v.SendMessage(res)
+	}
+}
+
+func (m *Manager) clientCount() int {
+	m.RLock()
+	defer m.RUnlock()
+	return len(m.clients)
+}
+
+// 管理接口，只应该监听内网地址
+func (m *Manager) serveAdmin(addr string) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/drain", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		go m.Drain()
+		w.WriteHeader(http.StatusAccepted)
+	})
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		zap.L().Error("connector admin listen serve err: ", zap.Error(err))
+	}
+}
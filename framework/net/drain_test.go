@@ -0,0 +1,50 @@
+package net
+
+import (
+	"framework/game"
+	"framework/protocol"
+	"testing"
+)
+
+// TestDrainRejectsRequest drain开始后新请求不再转发，回复错误和迁移目标，也不计入inflight
+func TestDrainRejectsRequest(t *testing.T) {
+	game.Conf = &game.Config{}
+	game.Conf.ServersConf.Connector = []*game.ConnectorConfig{
+		{ID: "connector-1", ServerType: "connector"},
+		{ID: "connector-2", ServerType: "connector", Host: "127.0.0.1", ClientPort: 12000},
+	}
+	defer func() { game.Conf = nil }()
+
+	m := NewManager()
+	m.ServerId = "connector-1"
+	m.draining.Store(true)
+	c := &recordConnection{benchConnection: benchConnection{session: NewSession("cid-1")}}
+	packet := &protocol.Packet{
+		Type: protocol.Data,
+		Body: protocol.Message{
+			Type:  protocol.Request,
+			ID:    1,
+			Route: "hall.userHandler.updateUserAddress",
+			Data:  []byte(`{}`),
+		},
+	}
+	if err := m.MessageHandler(packet, c); err == nil {
+		t.Fatalf("request accepted while draining")
+	}
+	if n := m.inflight.count(); n != 0 {
+		t.Fatalf("inflight = %d, want 0", n)
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if len(c.sent) != 2 {
+		t.Fatalf("sent %d packets, want migrate push and error response", len(c.sent))
+	}
+	push, err := protocol.Decode(c.sent[0], nil)
+	if err != nil || push.MessageBody().Route != MigrateRoute {
+		t.Fatalf("first packet is not migrate push, err: %v", err)
+	}
+	res, err := protocol.Decode(c.sent[1], nil)
+	if err != nil || !res.MessageBody().Error || res.MessageBody().ID != 1 {
+		t.Fatalf("second packet is not error response, err: %v", err)
+	}
+}
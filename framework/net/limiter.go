@@ -0,0 +1,150 @@
+package net
+
+import (
+	"encoding/json"
+	"framework/game"
+	"strings"
+	"sync"
+	"time"
+)
+
+const violationWindow = time.Minute
+
+// tokenBucket 令牌桶，rate为每秒生成的令牌数，burst为桶的容量
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if burst <= 0 {
+		burst = int(rate) + 1
+	}
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+func (b *tokenBucket) allow(now time.Time) bool {
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// routeBucket 路由级别的令牌桶，field不为空时只限制消息体中field为values之一的消息
+type routeBucket struct {
+	field  string
+	values map[string]bool
+	bucket *tokenBucket
+}
+
+func (r *routeBucket) match(fields map[string]json.RawMessage) bool {
+	if r.field == "" {
+		return true
+	}
+	v, ok := fields[r.field]
+	return ok && r.values[strings.Trim(string(v), `"`)]
+}
+
+// RateLimiter 单个连接的限流器，包含连接级别和路由级别的令牌桶
+type RateLimiter struct {
+	sync.Mutex
+	conf        *game.RateLimitConfig
+	conn        *tokenBucket
+	routes      map[string][]*routeBucket // 只包含配置了的路由，创建后不再修改
+	violations  int
+	windowStart time.Time
+}
+
+func NewRateLimiter(conf *game.RateLimitConfig) *RateLimiter {
+	l := &RateLimiter{
+		conf:   conf,
+		routes: make(map[string][]*routeBucket),
+	}
+	if conf == nil {
+		return l
+	}
+	if conf.Rate > 0 {
+		l.conn = newTokenBucket(conf.Rate, conf.Burst)
+	}
+	for _, v := range conf.Routes {
+		if v.Rate <= 0 {
+			continue
+		}
+		values := make(map[string]bool, len(v.Values))
+		for _, value := range v.Values {
+			values[value] = true
+		}
+		l.routes[v.Route] = append(l.routes[v.Route], &routeBucket{
+			field:  v.Field,
+			values: values,
+			bucket: newTokenBucket(v.Rate, v.Burst),
+		})
+	}
+	return l
+}
+
+// Allow 连接级别的限流
+func (l *RateLimiter) Allow() bool {
+	if l == nil || l.conn == nil {
+		return true
+	}
+	l.Lock()
+	defer l.Unlock()
+	return l.conn.allow(time.Now())
+}
+
+// AllowRoute 路由级别的限流，没有配置的路由不限制，body为json格式的消息体
+func (l *RateLimiter) AllowRoute(route string, body []byte) bool {
+	if l == nil {
+		return true
+	}
+	rules, ok := l.routes[route]
+	if !ok {
+		return true
+	}
+	var fields map[string]json.RawMessage
+	for _, v := range rules {
+		if v.field != "" && fields == nil {
+			_ = json.Unmarshal(body, &fields)
+			break
+		}
+	}
+	l.Lock()
+	defer l.Unlock()
+	now := time.Now()
+	for _, v := range rules {
+		if v.match(fields) && !v.bucket.allow(now) {
+			return false
+		}
+	}
+	return true
+}
+
+// Violate 记录一次超限，返回是否需要踢出（一个统计周期内超限次数达到 MaxViolations）
+func (l *RateLimiter) Violate() bool {
+	if l == nil || l.conf == nil {
+		return false
+	}
+	l.Lock()
+	defer l.Unlock()
+	now := time.Now()
+	if now.Sub(l.windowStart) > violationWindow {
+		l.windowStart = now
+		l.violations = 0
+	}
+	l.violations++
+	return l.conf.MaxViolations > 0 && l.violations >= l.conf.MaxViolations
+}
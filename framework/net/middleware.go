@@ -0,0 +1,105 @@
+package net
+
+import (
+	"context"
+	"encoding/json"
+	"framework/remote"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Middleware 包装connector本地的 HandlerFunc，可以在handler前后执行逻辑或者直接返回错误
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// Use 注册全局中间件，对所有connector本地路由生效，需要在Run之前调用
+func (m *Manager) Use(middlewares ...Middleware) {
+	m.middlewares = append(m.middlewares, middlewares...)
+}
+
+// UseRoute 注册路由中间件，route和 ConnectorHandlers 的key一致，例如 entryHandler.entry
+func (m *Manager) UseRoute(route string, middlewares ...Middleware) {
+	m.routeMiddlewares[route] = append(m.routeMiddlewares[route], middlewares...)
+}
+
+// 全局中间件在外层，路由中间件在内层，同一层按照注册顺序从外到内执行
+func (m *Manager) buildChain() {
+	m.chain = make(LogicHandler, len(m.ConnectorHandlers))
+	for route, handler := range m.ConnectorHandlers {
+		middlewares := append(append([]Middleware{}, m.middlewares...), m.routeMiddlewares[route]...)
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			handler = middlewares[i](handler)
+		}
+		m.chain[route] = handler
+	}
+}
+
+// Auth 没有登录的连接直接返回err
+func Auth(err error) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, session *Session, body []byte) (any, error) {
+			if session.Uid == "" {
+				return nil, err
+			}
+			return next(ctx, session, body)
+		}
+	}
+}
+
+// Logging 记录handler返回的错误
+func Logging() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, session *Session, body []byte) (any, error) {
+			data, err := next(ctx, session, body)
+			if err != nil {
+				zap.L().Sugar().Warnf("client[%s] uid=%s traceId=%s handle err: %v", session.Cid, session.Uid, remote.TraceId(ctx), err)
+			}
+			return data, err
+		}
+	}
+}
+
+// Timing 处理时间超过slow时记录日志
+func Timing(slow time.Duration) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, session *Session, body []byte) (any, error) {
+			start := time.Now()
+			data, err := next(ctx, session, body)
+			if cost := time.Since(start); cost > slow {
+				zap.L().Sugar().Warnf("client[%s] slow handler cost=%v", session.Cid, cost)
+			}
+			return data, err
+		}
+	}
+}
+
+// Validator 请求参数实现该接口时由 Validate 中间件校验
+type Validator interface {
+	Validate() error
+}
+
+type requestKey struct{}
+
+// Validate 请求参数不能解析为T或者校验不通过时返回err，解析后的参数放到ctx中，handler通过 Request 获取
+func Validate[T any](err error) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, session *Session, body []byte) (any, error) {
+			req := new(T)
+			if e := json.Unmarshal(body, req); e != nil {
+				return nil, err
+			}
+			if v, ok := any(req).(Validator); ok {
+				if e := v.Validate(); e != nil {
+					return nil, err
+				}
+			}
+			return next(context.WithValue(ctx, requestKey{}, req), session, body)
+		}
+	}
+}
+
+// Request 返回 Validate[T] 解析的请求参数，路由没有注册 Validate[T] 时返回false
+func Request[T any](ctx context.Context) (*T, bool) {
+	req, ok := ctx.Value(requestKey{}).(*T)
+	return req, ok
+}
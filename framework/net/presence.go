@@ -0,0 +1,42 @@
+package net
+
+import (
+	"context"
+	"framework/remote"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const presenceTimeout = 3 * time.Second
+
+// SetPresence 设置共享的在线记录，没有设置时只在当前connector内保证单点登录
+func (m *Manager) SetPresence(presence remote.Presence) {
+	m.presence = presence
+}
+
+// 记录在线，返回之前登录的connector
+func (m *Manager) presenceLogin(session *Session) string {
+	if m.presence == nil {
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), presenceTimeout)
+	defer cancel()
+	prevServerId, _, err := m.presence.Login(ctx, session.Uid, m.ServerId, session.Cid)
+	if err != nil {
+		zap.L().Sugar().Errorf("presence login uid=%s err: %v", session.Uid, err)
+		return ""
+	}
+	return prevServerId
+}
+
+func (m *Manager) presenceLogout(session *Session) {
+	if m.presence == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), presenceTimeout)
+	defer cancel()
+	if err := m.presence.Logout(ctx, session.Uid, m.ServerId, session.Cid); err != nil {
+		zap.L().Sugar().Errorf("presence logout uid=%s err: %v", session.Uid, err)
+	}
+}
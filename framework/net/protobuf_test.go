@@ -0,0 +1,133 @@
+package net
+
+import (
+	"bytes"
+	"common/pb"
+	"encoding/json"
+	"framework/protocol"
+	"framework/remote"
+	"framework/serializer"
+	"testing"
+)
+
+// TestProtobufRoundTrip 握手协商protobuf后，请求和push按 common/api/client.proto 编解码，解出的json和json序列化一致
+func TestProtobufRoundTrip(t *testing.T) {
+	pb.Register()
+	m := NewManager()
+	m.dictionary.Add("ServerMessagePush")
+	c := &recordConnection{benchConnection: benchConnection{session: NewSession("cid-pb")}}
+	m.clients[c.session.Cid] = c
+
+	handshake := &protocol.Packet{
+		Type: protocol.Handshake,
+		Body: protocol.HandshakeBody{Sys: protocol.Sys{Serializer: serializer.Protobuf}},
+	}
+	if err := m.HandshakeHandler(handshake, c); err != nil {
+		t.Fatalf("handshake err: %v", err)
+	}
+	var res protocol.HandshakeResponse
+	if err := json.Unmarshal(c.sent[0][protocol.HeaderLen:], &res); err != nil || res.Sys.Serializer != serializer.Protobuf {
+		t.Fatalf("handshake serializer = %q, %v, want protobuf", res.Sys.Serializer, err)
+	}
+	codec, _ := serializer.Get(serializer.Protobuf)
+	pbCodec := codec.(serializer.RouteCodec)
+
+	// 客户端请求，超过2^53的int64不丢精度
+	route := "game.unionHandler.createRoom"
+	req := []byte(`{"unionID":9007199254740993,"gameRuleID":"r1","gameRule":{"addScores":[1,2],"bureau":6,"canEnter":true}}`)
+	body, err := pbCodec.EncodeRoute(serializer.ClientMessage, route, req)
+	if err != nil || body[0] == '{' {
+		t.Fatalf("encode request = %q, %v, want protobuf", body, err)
+	}
+	decoded, err := c.session.DecodeBody(route, body)
+	if err != nil {
+		t.Fatalf("decode request err: %v", err)
+	}
+	var createRoom struct {
+		UnionID  int64 `json:"unionID"`
+		GameRule struct {
+			AddScores []int `json:"addScores"`
+			BaseScore int   `json:"baseScore"`
+			Bureau    int   `json:"bureau"`
+		} `json:"gameRule"`
+	}
+	if err := json.Unmarshal(decoded, &createRoom); err != nil || createRoom.UnionID != 9007199254740993 || createRoom.GameRule.Bureau != 6 {
+		t.Fatalf("decoded request = %s, %v", decoded, err)
+	}
+	// 零值字段和json序列化一样输出
+	if !bytes.Contains(decoded, []byte(`"baseScore":0`)) {
+		t.Fatalf("decoded request = %s, want zero value fields", decoded)
+	}
+
+	// push按pushRouter编码，chairID为0时客户端也能解出
+	c.session.Uid = "uid-pb"
+	m.bindUid(c)
+	pushes := []struct {
+		data     string
+		protobuf bool
+	}{
+		{`{"pushRouter":"RoomMessagePush","type":401,"data":{"chairID":0}}`, true},
+		{`{"pushRouter":"UpdateUserInfoPush","gold":9958}`, true},
+		// [][]int表示不了，按json发送
+		{`{"pushRouter":"GameMessagePush","type":402,"data":{"handCards":[[1,2,3]]}}`, false},
+	}
+	for i, push := range pushes {
+		m.Response(&remote.Msg{
+			PushUser: []string{"uid-pb"},
+			Body: &protocol.Message{
+				Type:  protocol.Push,
+				Route: "ServerMessagePush",
+				Data:  []byte(push.data),
+			},
+		})
+		packet, err := protocol.Decode(c.sent[len(c.sent)-1], c.session.Dictionary())
+		if err != nil {
+			t.Fatalf("push %d decode packet err: %v", i, err)
+		}
+		data := packet.MessageBody().Data
+		if isJSON := data[0] == '{'; isJSON == push.protobuf {
+			t.Fatalf("push %d body = %q, want protobuf %v", i, data, push.protobuf)
+		}
+		if push.protobuf && len(data) >= len(push.data) {
+			t.Fatalf("push %d protobuf size %d, json size %d", i, len(data), len(push.data))
+		}
+		got, err := pbCodec.DecodeRoute(serializer.ServerMessage, "ServerMessagePush", data)
+		if err != nil {
+			t.Fatalf("push %d decode body err: %v", i, err)
+		}
+		if !jsonContains(t, got, []byte(push.data)) {
+			t.Fatalf("push %d = %s, want %s", i, got, push.data)
+		}
+	}
+}
+
+// jsonContains want中的字段在got中都存在并且值相同
+func jsonContains(t *testing.T, got, want []byte) bool {
+	var g, w any
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Fatalf("unmarshal %s err: %v", got, err)
+	}
+	if err := json.Unmarshal(want, &w); err != nil {
+		t.Fatalf("unmarshal %s err: %v", want, err)
+	}
+	return contains(g, w)
+}
+
+func contains(got, want any) bool {
+	wm, ok := want.(map[string]any)
+	if !ok {
+		gb, _ := json.Marshal(got)
+		wb, _ := json.Marshal(want)
+		return bytes.Equal(gb, wb)
+	}
+	gm, ok := got.(map[string]any)
+	if !ok {
+		return false
+	}
+	for k, v := range wm {
+		if !contains(gm[k], v) {
+			return false
+		}
+	}
+	return true
+}
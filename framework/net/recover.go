@@ -0,0 +1,54 @@
+package net
+
+import (
+	"context"
+	"errors"
+	errs "framework/err"
+	"framework/remote"
+	"runtime/debug"
+
+	"go.uber.org/zap"
+)
+
+// errPanic Recover 中间件捕获panic后返回的错误，错误码和 errs.InternalError 相同，call 据此统计panic次数
+var errPanic = errs.NewError(errs.InternalError.Code, errors.New(errs.InternalError.Error()))
+
+// Recover connector本地handler和内层中间件panic时记录堆栈并返回错误，注册在 Logging 之后，Logging 可以记录返回的错误
+// 没有注册时由 call 兜底
+func Recover() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, session *Session, body []byte) (data any, err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					zap.L().Sugar().Errorf("client[%s] handler panic, uid=%s: %v\n%s", session.Cid, session.Uid, r, debug.Stack())
+					data, err = nil, errPanic
+				}
+			}()
+			return next(ctx, session, body)
+		}
+	}
+}
+
+// call 执行connector本地handler，panic时记录堆栈并返回错误，不影响其他连接
+// handler的ctx带有请求的元数据，HandleTimeOut后或者handler返回后取消
+func (m *Manager) call(handler HandlerFunc, session *Session, body []byte) (data any, err error) {
+	ctx, cancel := context.WithTimeout(remote.NewContext(context.Background(), remote.Metadata{
+		TraceId: remote.NewTraceId(),
+		Uid:     session.Uid,
+		Cid:     session.Cid,
+		Src:     m.ServerId,
+	}), m.handleTimeout)
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			m.Stats.Panics.Add(1)
+			zap.L().Sugar().Errorf("client[%s] handler panic, uid=%s: %v\n%s", session.Cid, session.Uid, r, debug.Stack())
+			data, err = nil, errs.InternalError
+		}
+	}()
+	data, err = handler(ctx, session, body)
+	if err == errPanic {
+		m.Stats.Panics.Add(1)
+	}
+	return data, err
+}
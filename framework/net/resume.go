@@ -0,0 +1,115 @@
+package net
+
+import (
+	"framework/protocol"
+	"sync"
+	"time"
+)
+
+// 断线期间最多缓存的push数，超过时丢弃最早的
+const maxResumePushes = 256
+
+// parkedSession 断线后等待恢复的session
+type parkedSession struct {
+	session *Session
+	pushes  []*protocol.Message
+	timer   *time.Timer
+}
+
+// resumeStore 网络切换等短暂断线时保留session，宽限期内客户端用恢复令牌重连可以接回原来的session。
+// 只保存在当前connector，重连到其他connector时需要重新登录
+type resumeStore struct {
+	sync.Mutex
+	byToken map[string]*parkedSession
+	byUid   map[string]*parkedSession
+}
+
+func newResumeStore() *resumeStore {
+	return &resumeStore{
+		byToken: make(map[string]*parkedSession),
+		byUid:   make(map[string]*parkedSession),
+	}
+}
+
+// park 保存断线的session，宽限期内没有恢复时调用expire
+func (r *resumeStore) park(session *Session, grace time.Duration, expire func(session *Session)) {
+	token := session.issuedResumeToken()
+	p := &parkedSession{session: session}
+	r.Lock()
+	defer r.Unlock()
+	// 同一个uid只保留最后断开的session
+	if old, ok := r.byUid[session.Uid]; ok {
+		old.timer.Stop()
+		delete(r.byToken, old.session.issuedResumeToken())
+	}
+	r.byToken[token] = p
+	r.byUid[session.Uid] = p
+	p.timer = time.AfterFunc(grace, func() {
+		r.Lock()
+		cur, ok := r.byToken[token]
+		if ok && cur == p {
+			delete(r.byToken, token)
+			delete(r.byUid, session.Uid)
+		}
+		r.Unlock()
+		if ok && cur == p {
+			expire(session)
+		}
+	})
+}
+
+// take 取出令牌对应的session，过期或者不存在时返回nil。
+// 取出后仍然缓存发给该uid的push，直到 finish 交给新连接
+func (r *resumeStore) take(token string) *parkedSession {
+	if token == "" {
+		return nil
+	}
+	r.Lock()
+	defer r.Unlock()
+	p, ok := r.byToken[token]
+	if !ok || !p.timer.Stop() {
+		return nil
+	}
+	delete(r.byToken, token)
+	return p
+}
+
+// finish 结束恢复，返回断线期间缓存的push，之后不再缓存该uid的push
+func (r *resumeStore) finish(p *parkedSession) []*protocol.Message {
+	r.Lock()
+	defer r.Unlock()
+	if r.byUid[p.session.Uid] == p {
+		delete(r.byUid, p.session.Uid)
+	}
+	pushes := p.pushes
+	p.pushes = nil
+	return pushes
+}
+
+// takeUid 取出uid断线等待恢复的session，用户重新登录时调用
+func (r *resumeStore) takeUid(uid string) *parkedSession {
+	r.Lock()
+	defer r.Unlock()
+	p, ok := r.byUid[uid]
+	if !ok || !p.timer.Stop() {
+		return nil
+	}
+	delete(r.byToken, p.session.issuedResumeToken())
+	delete(r.byUid, uid)
+	return p
+}
+
+// buffer 缓存发给断线用户的push，用户没有断线等待恢复时返回false
+func (r *resumeStore) buffer(uid string, message *protocol.Message) bool {
+	r.Lock()
+	defer r.Unlock()
+	p, ok := r.byUid[uid]
+	if !ok {
+		return false
+	}
+	if len(p.pushes) >= maxResumePushes {
+		p.pushes = p.pushes[1:]
+	}
+	p.pushes = append(p.pushes, message)
+	return true
+}
@@ -0,0 +1,98 @@
+package net
+
+import (
+	"encoding/json"
+	"fmt"
+	"framework/protocol"
+	"framework/remote"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordConnection struct {
+	benchConnection
+	lock        sync.Mutex
+	sent        [][]byte
+	onHandshake func()
+}
+
+func (c *recordConnection) SendMessage(buf []byte) error {
+	c.lock.Lock()
+	c.sent = append(c.sent, buf)
+	c.lock.Unlock()
+	if protocol.PackageType(buf[0]) == protocol.Handshake && c.onHandshake != nil {
+		c.onHandshake()
+	}
+	return nil
+}
+
+func pushMsg(uid string, i int) *remote.Msg {
+	return &remote.Msg{
+		PushUser: []string{uid},
+		Body: &protocol.Message{
+			Type:  protocol.Push,
+			Route: "ServerMessagePush",
+			Data:  []byte(fmt.Sprintf(`{"i":%d}`, i)),
+		},
+	}
+}
+
+// TestResumeWithPush 恢复session时有新的push，握手回复最先发送，缓存的push先补发，新的push不丢失也不插队
+func TestResumeWithPush(t *testing.T) {
+	m := NewManager()
+	m.dictionary.Add("ServerMessagePush")
+
+	old := NewSession("cid-old")
+	old.Uid = "uid-1"
+	old.resumeToken = "token-1"
+	m.resume.park(old, time.Minute, func(*Session) {})
+	// 断线期间的push
+	m.Response(pushMsg("uid-1", 0))
+
+	c := &recordConnection{benchConnection: benchConnection{session: NewSession("cid-new")}}
+	m.clients[c.session.Cid] = c
+	// 握手回复发出后、缓存的push补发之前来了新的push
+	pushed := make(chan struct{})
+	c.onHandshake = func() {
+		go func() {
+			m.Response(pushMsg("uid-1", 1))
+			close(pushed)
+		}()
+		time.Sleep(20 * time.Millisecond)
+	}
+	handshake := &protocol.Packet{
+		Type: protocol.Handshake,
+		Body: protocol.HandshakeBody{Sys: protocol.Sys{ResumeToken: "token-1"}},
+	}
+	if err := m.HandshakeHandler(handshake, c); err != nil {
+		t.Fatalf("handshake err: %v", err)
+	}
+	<-pushed
+	m.Response(pushMsg("uid-1", 2))
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if len(c.sent) != 4 {
+		t.Fatalf("sent %d packets, want handshake and 3 pushes", len(c.sent))
+	}
+	if protocol.PackageType(c.sent[0][0]) != protocol.Handshake {
+		t.Fatalf("first packet is not handshake response")
+	}
+	dict := c.session.Dictionary()
+	for i, buf := range c.sent[1:] {
+		packet, err := protocol.Decode(buf, dict)
+		if err != nil {
+			t.Fatalf("decode push %d err: %v", i, err)
+		}
+		var data struct {
+			I int `json:"i"`
+		}
+		if err := json.Unmarshal(packet.MessageBody().Data, &data); err != nil {
+			t.Fatalf("unmarshal push %d err: %v", i, err)
+		}
+		if data.I != i {
+			t.Fatalf("push %d = %d, want in order", i, data.I)
+		}
+	}
+}
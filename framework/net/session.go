@@ -1,21 +1,43 @@
 package net
 
-import "sync"
+import (
+	"encoding/json"
+	"framework/protocol"
+	"framework/serializer"
+	"maps"
+	"sync"
+
+	"github.com/google/uuid"
+)
 
 type Session struct {
 	sync.RWMutex
-	Cid  string
-	Uid  string
-	data map[string]any
+	Cid        string
+	Uid        string
+	data       map[string]any
+	serializer serializer.Serializer
+	dict       *protocol.Dictionary
+	compress   bool
+	// 断线重连时用来恢复session，登录后下发给客户端
+	resumeToken string
+	takenOver   bool // 同一个uid在其他连接登录，被顶替了
 }
 
 func NewSession(cid string) *Session {
 	return &Session{
-		Cid:  cid,
-		data: make(map[string]any),
+		Cid:        cid,
+		data:       make(map[string]any),
+		serializer: serializer.Default(),
 	}
 }
 
+// SetUid 登录或者回滚登录时设置uid，和 resume、push 读取uid互斥
+func (s *Session) SetUid(uid string) {
+	s.Lock()
+	defer s.Unlock()
+	s.Uid = uid
+}
+
 func (s *Session) Set(key string, value any) {
 	s.Lock()
 	defer s.Unlock()
@@ -23,12 +45,19 @@ func (s *Session) Set(key string, value any) {
 }
 
 func (s *Session) Get(key string) (any, bool) {
-	s.RLocker()
+	s.RLock()
 	defer s.RUnlock()
 	val, ok := s.data[key]
 	return val, ok
 }
 
+// GetData 返回session数据的副本，转发给node节点
+func (s *Session) GetData() map[string]any {
+	s.RLock()
+	defer s.RUnlock()
+	return maps.Clone(s.data)
+}
+
 func (s *Session) SetData(uid string, data map[string]any) {
 	s.Lock()
 	defer s.Unlock()
@@ -38,3 +67,124 @@ func (s *Session) SetData(uid string, data map[string]any) {
 		}
 	}
 }
+
+// ResumeToken 返回断线重连用的恢复令牌，第一次调用时生成，应该在登录成功后返回给客户端
+func (s *Session) ResumeToken() string {
+	s.Lock()
+	defer s.Unlock()
+	if s.resumeToken == "" {
+		s.resumeToken = uuid.NewString()
+	}
+	return s.resumeToken
+}
+
+// 没有下发过令牌的session断线后不保留
+func (s *Session) issuedResumeToken() string {
+	s.RLock()
+	defer s.RUnlock()
+	return s.resumeToken
+}
+
+// resume 接回断线前的session数据并更换恢复令牌
+func (s *Session) resume(old *Session) string {
+	data := old.GetData()
+	s.Lock()
+	defer s.Unlock()
+	s.Uid = old.Uid
+	s.data = data
+	s.resumeToken = uuid.NewString()
+	return s.resumeToken
+}
+
+// inherit 继承被顶替的session数据，新session已有的数据不覆盖
+func (s *Session) inherit(data map[string]any) {
+	s.Lock()
+	defer s.Unlock()
+	for k, v := range data {
+		if _, ok := s.data[k]; !ok {
+			s.data[k] = v
+		}
+	}
+}
+
+func (s *Session) markTakenOver() {
+	s.Lock()
+	defer s.Unlock()
+	s.takenOver = true
+}
+
+func (s *Session) isTakenOver() bool {
+	s.RLock()
+	defer s.RUnlock()
+	return s.takenOver
+}
+
+// SetSerializer 握手时设置该连接协商好的序列化器
+func (s *Session) SetSerializer(sz serializer.Serializer) {
+	s.Lock()
+	defer s.Unlock()
+	s.serializer = sz
+}
+
+func (s *Session) Serializer() serializer.Serializer {
+	s.RLock()
+	defer s.RUnlock()
+	if s.serializer == nil {
+		return serializer.Default()
+	}
+	return s.serializer
+}
+
+// SetDictionary 握手时设置该连接使用的路由压缩字典
+func (s *Session) SetDictionary(dict *protocol.Dictionary) {
+	s.Lock()
+	defer s.Unlock()
+	s.dict = dict
+}
+
+func (s *Session) Dictionary() *protocol.Dictionary {
+	s.RLock()
+	defer s.RUnlock()
+	return s.dict
+}
+
+// SetCompress 握手时设置是否开启下行压缩
+func (s *Session) SetCompress(compress bool) {
+	s.Lock()
+	defer s.Unlock()
+	s.compress = compress
+}
+
+func (s *Session) Compress() bool {
+	s.RLock()
+	defer s.RUnlock()
+	return s.compress
+}
+
+// DecodeBody 将客户端发送到route的消息体转换为json，connector handler和node节点内部统一使用json
+func (s *Session) DecodeBody(route string, data []byte) ([]byte, error) {
+	sz := s.Serializer()
+	if len(data) == 0 || sz.Name() == serializer.JSON {
+		return data, nil
+	}
+	if codec, ok := sz.(serializer.RouteCodec); ok {
+		return codec.DecodeRoute(serializer.ClientMessage, route, data)
+	}
+	var raw json.RawMessage
+	if err := sz.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// EncodeBody 将回复或推送到route的json消息体转换为客户端协商的格式
+func (s *Session) EncodeBody(route string, data []byte) ([]byte, error) {
+	sz := s.Serializer()
+	if len(data) == 0 || sz.Name() == serializer.JSON {
+		return data, nil
+	}
+	if codec, ok := sz.(serializer.RouteCodec); ok {
+		return codec.EncodeRoute(serializer.ServerMessage, route, data)
+	}
+	return sz.Marshal(json.RawMessage(data))
+}
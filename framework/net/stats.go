@@ -0,0 +1,19 @@
+package net
+
+import "sync/atomic"
+
+// Stats connector运行时的统计指标
+type Stats struct {
+	CompressedMessages atomic.Int64 // 压缩下发的消息数
+	CompressRawBytes   atomic.Int64 // 压缩前的字节数
+	CompressSavedBytes atomic.Int64 // 压缩节省的字节数
+	RateLimited        atomic.Int64 // 被限流的数据包数
+	WriteQueued        atomic.Int64 // 所有连接写队列中待写出的消息数
+	WriteQueueMax      atomic.Int64 // 单个连接写队列达到过的最大长度
+	PushDropped        atomic.Int64 // 写队列满时丢弃的push数
+	PushCoalesced      atomic.Int64 // 写队列满时合并的push数
+	SlowDisconnects    atomic.Int64 // 写队列满被断开的连接数
+	Rejected           atomic.Int64 // 超过连接数限制被拒绝的连接数
+	UpgradeFailed      atomic.Int64 // websocket升级失败的次数
+	Panics             atomic.Int64 // connector本地handler发生panic的次数
+}
@@ -0,0 +1,91 @@
+package net
+
+import (
+	"encoding/json"
+	"framework/protocol"
+	"framework/remote"
+	"maps"
+
+	"go.uber.org/zap"
+)
+
+var duplicateLogin = protocol.KickReason{
+	Code:   protocol.KickDuplicateLogin,
+	Reason: "logged in elsewhere",
+}
+
+// takeover 同一个uid只保留最新登录的连接，踢出旧连接并把旧session的数据迁移到新连接
+func (m *Manager) takeover(c Connection) {
+	session := c.GetSession()
+	uid := session.Uid
+	for _, old := range m.uidConnections(uid, session.Cid) {
+		session.inherit(old.GetSession().GetData())
+		m.replace(old)
+	}
+	// 断线等待恢复的session也不再需要
+	if parked := m.resume.takeUid(uid); parked != nil {
+		session.inherit(parked.session.GetData())
+	}
+	// 登录在其他connector上时通知对方踢出并把session数据发过来
+	prevServerId := m.presenceLogin(session)
+	if prevServerId == "" || prevServerId == m.ServerId || m.RemoteCli == nil {
+		return
+	}
+	msg := &remote.Msg{
+		Type: remote.TakeoverType,
+		Cid:  session.Cid,
+		Uid:  uid,
+		Src:  m.ServerId,
+		Dst:  prevServerId,
+	}
+	data, _ := json.Marshal(msg)
+	if err := m.RemoteCli.SendMsg(prevServerId, data); err != nil {
+		zap.L().Sugar().Errorf("send takeover msg to %s err: %v", prevServerId, err)
+	}
+}
+
+// handOver 用户在其他connector上重新登录，踢出本地的连接并把session数据发给新的connector
+func (m *Manager) handOver(msg remote.Msg) {
+	data := make(map[string]any)
+	for _, old := range m.uidConnections(msg.Uid, "") {
+		maps.Copy(data, old.GetSession().GetData())
+		m.replace(old)
+	}
+	if parked := m.resume.takeUid(msg.Uid); parked != nil {
+		maps.Copy(data, parked.session.GetData())
+	}
+	if len(data) == 0 || m.RemoteCli == nil {
+		return
+	}
+	res := &remote.Msg{
+		Type:        remote.SessionType,
+		Cid:         msg.Cid,
+		Uid:         msg.Uid,
+		Src:         m.ServerId,
+		Dst:         msg.Src,
+		SessionData: data,
+	}
+	body, _ := json.Marshal(res)
+	if err := m.RemoteCli.SendMsg(msg.Src, body); err != nil {
+		zap.L().Sugar().Errorf("send session data to %s err: %v", msg.Src, err)
+	}
+}
+
+// replace 被顶替的连接断开时不通知node节点下线
+func (m *Manager) replace(old Connection) {
+	old.GetSession().markTakenOver()
+	m.Kick(old.GetSession().Cid, duplicateLogin)
+}
+
+// uid对应的所有连接，排除exceptCid
+func (m *Manager) uidConnections(uid, exceptCid string) []Connection {
+	m.RLock()
+	defer m.RUnlock()
+	conns := make([]Connection, 0, len(m.users[uid]))
+	for cid, v := range m.users[uid] {
+		if cid != exceptCid {
+			conns = append(conns, v)
+		}
+	}
+	return conns
+}
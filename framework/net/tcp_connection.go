@@ -0,0 +1,150 @@
+package net
+
+import (
+	"fmt"
+	"framework/protocol"
+	gonet "net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const readBufferSize = 4096
+
+// TcpConnection 原生客户端使用的tcp连接，数据包格式与websocket相同
+type TcpConnection struct {
+	connBase
+	Cid        string
+	Conn       gonet.Conn
+	Manager    *Manager
+	ReadChan   chan *MsgPack
+	writeQueue *writeQueue
+	Session    *Session
+	decoder    *protocol.Decoder
+	closeChan  chan struct{}
+	closeOnce  sync.Once
+}
+
+func NewTcpConnection(conn gonet.Conn, manager *Manager) *TcpConnection {
+	cid := fmt.Sprintf("%s-%s-%d", uuid.NewString(), manager.ServerId, atomic.AddUint64(&cidBase, 1))
+	t := &TcpConnection{
+		Cid:       cid,
+		Conn:      conn,
+		Manager:   manager,
+		ReadChan:  manager.ClientReadChan,
+		Session:   NewSession(cid),
+		decoder:   protocol.NewLimitDecoder(manager.maxPacketSize - protocol.HeaderLen),
+		closeChan: make(chan struct{}),
+	}
+	t.limiter = NewRateLimiter(manager.rateLimit)
+	t.ip = remoteIP(conn.RemoteAddr().String())
+	t.writeQueue = newWriteQueue(manager.writeQueueSize, manager.slowPolicy, &manager.Stats)
+	t.Touch()
+	return t
+}
+
+func (t *TcpConnection) Run() {
+	go t.readMessage()
+	go t.writeMessage()
+}
+
+func (t *TcpConnection) GetSession() *Session {
+	return t.Session
+}
+
+// SendMessage 写入写队列，不会阻塞，写队列满时按照 SlowPolicy 处理
+func (t *TcpConnection) SendMessage(buf []byte) error {
+	select {
+	case <-t.closeChan:
+		return ErrConnectionClosed
+	default:
+	}
+	if !t.writeQueue.push(buf) {
+		zap.L().Sugar().Warnf("client[%s] write queue full, disconnect slow consumer", t.Cid)
+		t.Close()
+		return ErrSlowConsumer
+	}
+	return nil
+}
+
+// SendAndClose 发送完写队列中已有的消息和buf之后关闭连接
+func (t *TcpConnection) SendAndClose(buf []byte) error {
+	_ = t.SendMessage(buf)
+	// nil 作为关闭标识
+	_ = t.SendMessage(nil)
+	// 写出阻塞时强制关闭
+	time.AfterFunc(writeWait, t.Close)
+	return nil
+}
+
+func (t *TcpConnection) readMessage() {
+	defer func() {
+		t.Manager.removeClient(t)
+	}()
+	buf := make([]byte, readBufferSize)
+	for {
+		// tcp没有ping/pong，收到任何数据（包括心跳包）都刷新读超时
+		if err := t.Conn.SetReadDeadline(time.Now().Add(t.Manager.heartbeatTimeout())); err != nil {
+			zap.L().Error("SetReadDeadline err: ", zap.Error(err))
+			return
+		}
+		n, err := t.Conn.Read(buf)
+		if err != nil {
+			zap.L().Error("read tcp message failed", zap.Error(err))
+			return
+		}
+		packets, err := t.decoder.Decode(buf[:n])
+		if err != nil {
+			zap.L().Sugar().Errorf("client[%s] decode tcp packet err: %v", t.Cid, err)
+			return
+		}
+		for _, packet := range packets {
+			if !t.Manager.allowClientPack(t, packet) {
+				continue
+			}
+			if t.ReadChan != nil {
+				t.ReadChan <- &MsgPack{
+					Cid:  t.Cid,
+					Body: packet,
+				}
+			}
+		}
+	}
+}
+
+func (t *TcpConnection) writeMessage() {
+	for {
+		select {
+		case <-t.writeQueue.notify:
+			for _, msg := range t.writeQueue.pop() {
+				// 关闭标识，之前的消息已经全部写出
+				if msg == nil {
+					t.Close()
+					return
+				}
+				if err := t.Conn.SetWriteDeadline(time.Now().Add(writeWait)); err != nil {
+					zap.L().Sugar().Errorf("client[%s] SetWriteDeadline err :%v", t.Cid, err)
+				}
+				if _, err := t.Conn.Write(msg); err != nil {
+					zap.L().Sugar().Errorf("client[%s] write message err: %v", t.Cid, err)
+					t.Close()
+					return
+				}
+			}
+		case <-t.closeChan:
+			return
+		}
+	}
+}
+
+func (t *TcpConnection) Close() {
+	t.closeOnce.Do(func() {
+		close(t.closeChan)
+		if t.Conn != nil {
+			_ = t.Conn.Close()
+		}
+	})
+}
@@ -0,0 +1,127 @@
+package net
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+)
+
+// 目录中的文件变化后等待一段时间再加载，证书更新一般是连续的多个文件操作
+const certReloadDelay = 500 * time.Millisecond
+
+// certLoader 证书热加载，证书文件变化后新的tls握手使用新证书，已经建立的连接不受影响
+type certLoader struct {
+	certFile string
+	keyFile  string
+	cert     atomic.Pointer[tls.Certificate]
+	sum      []byte // 当前证书和私钥内容的hash，内容没有变化时不重新加载
+}
+
+func newCertLoader(certFile, keyFile string) (*certLoader, error) {
+	l := &certLoader{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+	if _, err := l.load(); err != nil {
+		return nil, err
+	}
+	go l.watch()
+	return l, nil
+}
+
+// load 返回证书是否有变化，只在watch的goroutine中调用
+func (l *certLoader) load() (bool, error) {
+	// 按路径读取，路径是软链接时读取的是链接指向的新文件
+	certPEM, err := os.ReadFile(l.certFile)
+	if err != nil {
+		return false, err
+	}
+	keyPEM, err := os.ReadFile(l.keyFile)
+	if err != nil {
+		return false, err
+	}
+	h := sha256.New()
+	h.Write(certPEM)
+	h.Write(keyPEM)
+	sum := h.Sum(nil)
+	if bytes.Equal(sum, l.sum) {
+		return false, nil
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return false, err
+	}
+	l.cert.Store(&cert)
+	l.sum = sum
+	return true, nil
+}
+
+// 监听证书所在目录，证书更新工具一般是替换文件或者切换软链接而不是直接写入，
+// 例如k8s的secret切换 ..data 链接，certbot更新 live 目录下的链接，
+// 所以目录中任何文件变化都重新读取，内容没有变化时忽略
+func (l *certLoader) watch() {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		zap.L().Error("create cert watcher err: ", zap.Error(err))
+		return
+	}
+	defer watcher.Close()
+	dirs := map[string]bool{
+		filepath.Dir(l.certFile): true,
+		filepath.Dir(l.keyFile):  true,
+	}
+	for dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			zap.L().Error("watch cert dir err: ", zap.Error(err))
+			return
+		}
+	}
+	reload := time.NewTimer(certReloadDelay)
+	reload.Stop()
+	defer reload.Stop()
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Has(fsnotify.Chmod) {
+				continue
+			}
+			reload.Reset(certReloadDelay)
+		case <-reload.C:
+			// 证书和私钥可能先后更新，加载失败时保留旧证书，等待下一次变化
+			changed, err := l.load()
+			if err != nil {
+				zap.L().Error("reload cert err: ", zap.Error(err))
+				continue
+			}
+			if changed {
+				zap.L().Info("reload cert success: " + l.certFile)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			zap.L().Error("cert watcher err: ", zap.Error(err))
+		}
+	}
+}
+
+func (l *certLoader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return l.cert.Load(), nil
+}
+
+func (l *certLoader) tlsConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: l.GetCertificate,
+	}
+}
@@ -0,0 +1,142 @@
+package net
+
+import (
+	"errors"
+	"framework/protocol"
+	"sync"
+)
+
+var (
+	ErrSlowConsumer     = errors.New("write queue full, slow consumer disconnected")
+	ErrConnectionClosed = errors.New("connection closed")
+)
+
+type SlowPolicy string
+
+// 写队列满时对push消息的处理策略，response、kick等消息超过 size 后仍然入队，超过 size*hardLimitFactor 时断开连接
+const (
+	PolicyDropOldest SlowPolicy = "dropOldest" // 丢弃最早的push
+	PolicyCoalesce   SlowPolicy = "coalesce"   // 合并到上一条push中一起写出，合并不了时丢弃最早的push
+	PolicyDisconnect SlowPolicy = "disconnect" // 直接断开连接
+)
+
+const (
+	defaultWriteQueueSize = 1024
+	maxCoalesceSize       = 64 * 1024
+	hardLimitFactor       = 2 // response等不能丢弃的消息最多占用的队列长度倍数
+)
+
+// writeQueue 有界且不阻塞的写队列，避免一个慢连接阻塞 Manager.Response
+type writeQueue struct {
+	sync.Mutex
+	items  [][]byte
+	size   int
+	policy SlowPolicy
+	stats  *Stats
+	notify chan struct{}
+}
+
+func newWriteQueue(size int, policy SlowPolicy, stats *Stats) *writeQueue {
+	if size <= 0 {
+		size = defaultWriteQueueSize
+	}
+	if policy == "" {
+		policy = PolicyDropOldest
+	}
+	return &writeQueue{
+		items:  make([][]byte, 0, 16),
+		size:   size,
+		policy: policy,
+		stats:  stats,
+		notify: make(chan struct{}, 1),
+	}
+}
+
+// push 入队，返回false表示按照策略需要断开连接
+func (q *writeQueue) push(buf []byte) bool {
+	q.Lock()
+	defer q.Unlock()
+	if len(q.items) >= q.size && isPush(buf) {
+		switch q.policy {
+		case PolicyDisconnect:
+			q.stats.SlowDisconnects.Add(1)
+			return false
+		case PolicyCoalesce:
+			if q.coalesce(buf) {
+				q.stats.PushCoalesced.Add(1)
+				return true
+			}
+		}
+		// 队列中没有可以丢弃的push时丢弃新的push
+		if !q.dropOldest() {
+			q.stats.PushDropped.Add(1)
+			return true
+		}
+	}
+	// response等消息不能丢弃，一直写不出去时断开连接
+	if len(q.items) >= q.size*hardLimitFactor {
+		q.stats.SlowDisconnects.Add(1)
+		return false
+	}
+	q.items = append(q.items, buf)
+	q.stats.WriteQueued.Add(1)
+	q.updateMax(int64(len(q.items)))
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// 多个数据包可以合并在同一帧中写出，客户端按包头依次解析
+func (q *writeQueue) coalesce(buf []byte) bool {
+	last := len(q.items) - 1
+	if last < 0 || !isPush(q.items[last]) || len(q.items[last])+len(buf) > maxCoalesceSize {
+		return false
+	}
+	merged := make([]byte, 0, len(q.items[last])+len(buf))
+	merged = append(merged, q.items[last]...)
+	q.items[last] = append(merged, buf...)
+	return true
+}
+
+// dropOldest 丢弃最早的push，队列中没有push时返回false
+func (q *writeQueue) dropOldest() bool {
+	for i, v := range q.items {
+		if isPush(v) {
+			q.items = append(q.items[:i], q.items[i+1:]...)
+			q.stats.WriteQueued.Add(-1)
+			q.stats.PushDropped.Add(1)
+			return true
+		}
+	}
+	return false
+}
+
+// 多个连接同时更新，用CAS避免较小的值覆盖较大的值
+func (q *writeQueue) updateMax(depth int64) {
+	for {
+		cur := q.stats.WriteQueueMax.Load()
+		if depth <= cur || q.stats.WriteQueueMax.CompareAndSwap(cur, depth) {
+			return
+		}
+	}
+}
+
+// pop 取出所有待写的数据
+func (q *writeQueue) pop() [][]byte {
+	q.Lock()
+	defer q.Unlock()
+	items := q.items
+	q.items = make([][]byte, 0, 16)
+	q.stats.WriteQueued.Add(-int64(len(items)))
+	return items
+}
+
+// 根据数据包头和消息flag判断是否是push消息
+func isPush(buf []byte) bool {
+	if len(buf) <= protocol.HeaderLen || protocol.PackageType(buf[0]) != protocol.Data {
+		return false
+	}
+	return protocol.MessageType((buf[protocol.HeaderLen]>>1)&protocol.TypeMask) == protocol.Push
+}
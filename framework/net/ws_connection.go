@@ -2,6 +2,7 @@ package net
 
 import (
 	"fmt"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -11,36 +12,43 @@ import (
 )
 
 var (
-	cidBase        uint64 = 10000
-	maxMessageSize int64  = 1024
-	pongWait              = 10 * time.Second
-	writeWait             = 10 * time.Second
-	pingInterval          = (pongWait * 9) / 10
+	cidBase   uint64 = 10000
+	writeWait        = 10 * time.Second
 )
 
 type WsConnection struct {
+	connBase
 	Cid        string
 	Conn       *websocket.Conn
 	Manager    *Manager
 	ReadChan   chan *MsgPack
-	WriteChan  chan []byte
+	writeQueue *writeQueue
 	Session    *Session
 	pingTicker *time.Ticker
+	closeChan  chan struct{}
+	closeOnce  sync.Once
 }
 
 func NewWsConnection(conn *websocket.Conn, manager *Manager) *WsConnection {
 	cid := fmt.Sprintf("%s-%s-%d", uuid.NewString(), manager.ServerId, atomic.AddUint64(&cidBase, 1))
-	return &WsConnection{
+	w := &WsConnection{
 		Cid:       cid,
 		Conn:      conn,
 		Manager:   manager,
 		ReadChan:  manager.ClientReadChan,
-		WriteChan: make(chan []byte, 1024),
 		Session:   NewSession(cid),
+		closeChan: make(chan struct{}),
 	}
+	w.limiter = NewRateLimiter(manager.rateLimit)
+	w.ip = remoteIP(conn.RemoteAddr().String())
+	w.writeQueue = newWriteQueue(manager.writeQueueSize, manager.slowPolicy, &manager.Stats)
+	w.Touch()
+	return w
 }
 
 func (w *WsConnection) Run() {
+	// 每个心跳周期ping一次，读超时和tcp一样是 heartbeatTimeout
+	w.pingTicker = time.NewTicker(w.Manager.heartbeat)
 	go w.readMessage()
 	go w.writeMessage()
 	// 心跳检测
@@ -51,8 +59,28 @@ func (w *WsConnection) GetSession() *Session {
 	return w.Session
 }
 
+// SendMessage 写入写队列，不会阻塞，写队列满时按照 SlowPolicy 处理
 func (w *WsConnection) SendMessage(buf []byte) error {
-	w.WriteChan <- buf
+	select {
+	case <-w.closeChan:
+		return ErrConnectionClosed
+	default:
+	}
+	if !w.writeQueue.push(buf) {
+		zap.L().Sugar().Warnf("client[%s] write queue full, disconnect slow consumer", w.Cid)
+		w.Close()
+		return ErrSlowConsumer
+	}
+	return nil
+}
+
+// SendAndClose 发送完写队列中已有的消息和buf之后关闭连接
+func (w *WsConnection) SendAndClose(buf []byte) error {
+	_ = w.SendMessage(buf)
+	// nil 作为关闭标识
+	_ = w.SendMessage(nil)
+	// 写出阻塞时强制关闭
+	time.AfterFunc(writeWait, w.Close)
 	return nil
 }
 
@@ -60,12 +88,13 @@ func (w *WsConnection) readMessage() {
 	defer func() {
 		w.Manager.removeClient(w)
 	}()
-	w.Conn.SetReadLimit(maxMessageSize)
-	if err := w.Conn.SetReadDeadline(time.Now().Add(pongWait)); err != nil {
-		zap.L().Error("SetReadDeadline err: ", zap.Error(err))
-		return
-	}
+	w.Conn.SetReadLimit(int64(w.Manager.maxPacketSize))
 	for {
+		// 收到任何消息（包括pong）都刷新读超时
+		if err := w.Conn.SetReadDeadline(time.Now().Add(w.Manager.heartbeatTimeout())); err != nil {
+			zap.L().Error("SetReadDeadline err: ", zap.Error(err))
+			return
+		}
 		messageType, msg, err := w.Conn.ReadMessage()
 		if err != nil {
 			zap.L().Error("read websocket message failed")
@@ -73,6 +102,9 @@ func (w *WsConnection) readMessage() {
 		}
 		// 只接受二进制数据
 		if messageType == websocket.BinaryMessage {
+			if !w.Manager.allowClientPack(w, msg) {
+				continue
+			}
 			if w.ReadChan != nil {
 				w.ReadChan <- &MsgPack{
 					Cid:  w.Cid,
@@ -86,19 +118,28 @@ func (w *WsConnection) readMessage() {
 }
 
 func (w *WsConnection) writeMessage() {
-	w.pingTicker = time.NewTicker(pingInterval)
 	for {
 		select {
-		case msg, ok := <-w.WriteChan:
-			// 没有数据
-			if !ok {
-				if err := w.Conn.WriteMessage(websocket.CloseMessage, nil); err != nil {
-					zap.L().Error("connection closed, err: ", zap.Error(err))
+		case <-w.writeQueue.notify:
+			for _, msg := range w.writeQueue.pop() {
+				// 关闭标识，之前的消息已经全部写出
+				if msg == nil {
+					if err := w.Conn.WriteControl(websocket.CloseMessage,
+						websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(writeWait)); err != nil {
+						zap.L().Error("connection closed, err: ", zap.Error(err))
+					}
+					w.Close()
+					return
+				}
+				if err := w.Conn.SetWriteDeadline(time.Now().Add(writeWait)); err != nil {
+					zap.L().Sugar().Errorf("client[%s] SetWriteDeadline err :%v", w.Cid, err)
+				}
+				// 读数据
+				if err := w.Conn.WriteMessage(websocket.BinaryMessage, msg); err != nil {
+					zap.L().Sugar().Errorf("client[%s] write message err: %v", w.Cid, err)
+					w.Close()
+					return
 				}
-			}
-			// 读数据
-			if err := w.Conn.WriteMessage(websocket.BinaryMessage, msg); err != nil {
-				zap.L().Sugar().Errorf("client[%s] write message err: %v", w.Cid, err)
 			}
 		case <-w.pingTicker.C:
 			if err := w.Conn.SetWriteDeadline(time.Now().Add(writeWait)); err != nil {
@@ -108,23 +149,28 @@ func (w *WsConnection) writeMessage() {
 				zap.L().Sugar().Errorf("client[%s] ping err :%v", w.Cid, err)
 				w.Close()
 			}
+		case <-w.closeChan:
+			return
 		}
 	}
 
 }
 
 func (w *WsConnection) Close() {
-	if w.Conn != nil {
-		w.Conn.Close()
-	}
-	if w.pingTicker != nil {
-		w.pingTicker.Stop()
-	}
+	w.closeOnce.Do(func() {
+		close(w.closeChan)
+		if w.Conn != nil {
+			w.Conn.Close()
+		}
+		if w.pingTicker != nil {
+			w.pingTicker.Stop()
+		}
+	})
 }
 
 func (w *WsConnection) PongHandler(data string) error {
 	zap.L().Info("pong...")
-	if err := w.Conn.SetReadDeadline(time.Now().Add(pongWait)); err != nil {
+	if err := w.Conn.SetReadDeadline(time.Now().Add(w.Manager.heartbeatTimeout())); err != nil {
 		return err
 	}
 	return nil
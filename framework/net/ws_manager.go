@@ -2,16 +2,23 @@ package net
 
 import (
 	"common/utils"
+	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
+	errs "framework/err"
 	"framework/game"
 	"framework/protocol"
 	"framework/remote"
+	"framework/serializer"
 	"math/rand"
+	gonet "net"
 	"net/http"
+	"net/url"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -20,17 +27,23 @@ import (
 
 var (
 	websocketUpgrade = websocket.Upgrader{
-		CheckOrigin: func(r *http.Request) bool {
-			return true
-		},
 		ReadBufferSize:  1024,
 		WriteBufferSize: 1024,
 	}
 )
 
+const (
+	defaultHeartbeat        = 3 * time.Second
+	defaultHeartbeatMissed  = 3
+	defaultHandshakeTimeout = 10 * time.Second
+	defaultMaxPacketSize    = 1024
+	defaultHandleTimeout    = 5 * time.Second
+)
+
 type CheckOriginHandler func(r *http.Request) bool
 
-type HandlerFunc func(session *Session, body []byte) (any, error)
+// HandlerFunc connector本地handler，ctx在HandleTimeOut后取消，可以用 remote.FromContext 取出traceId、uid、cid
+type HandlerFunc func(ctx context.Context, session *Session, body []byte) (any, error)
 
 type LogicHandler map[string]HandlerFunc
 
@@ -42,64 +55,357 @@ type Manager struct {
 	ServerId           string // 在connector赋值
 	CheckOriginHandler CheckOriginHandler
 	clients            map[string]Connection
-	RemoteCli          remote.Client // 在connector赋值
+	users              map[string]map[string]Connection // uid -> cid -> 连接，登录后建立，和clients一起由锁保护
+	RemoteCli          remote.Client                    // 在connector赋值
 	handlers           map[protocol.PackageType]EventHandler
 	ConnectorHandlers  LogicHandler // 在connector赋值
 	ClientReadChan     chan *MsgPack
 	RemoteReadChan     chan []byte
 	RemotePushChan     chan *remote.Msg
+	dictionary         *protocol.DictBuilder
+	compressThreshold  int
+	allowOrigins       []string
+	certLoader         *certLoader
+	heartbeat          time.Duration // 握手时下发给客户端的心跳间隔
+	heartbeatMissed    int           // 连续多少个心跳周期没有收到数据包就断开连接
+	handshakeTimeout   time.Duration
+	maxPacketSize      int // 客户端数据包的最大长度，包含包头
+	rateLimit          *game.RateLimitConfig
+	writeQueueSize     int
+	slowPolicy         SlowPolicy
+	admission          *admission
+	server             *http.Server
+	tcpListener        gonet.Listener
+	inflight           *inflight
+	draining           atomic.Bool
+	drained            chan struct{}
+	drainTimeout       time.Duration
+	handleTimeout      time.Duration
+	resume             *resumeStore
+	resumeGrace        time.Duration // 断线后保留session的时间，0不保留
+	presence           remote.Presence
+	middlewares        []Middleware
+	routeMiddlewares   map[string][]Middleware
+	chain              LogicHandler // 包装了中间件的 ConnectorHandlers，Run时生成
+	Stats              Stats
 }
 
 func NewManager() *Manager {
 	return &Manager{
-		ClientReadChan: make(chan *MsgPack, 1024),
-		clients:        make(map[string]Connection),
-		handlers:       make(map[protocol.PackageType]EventHandler),
-		RemoteReadChan: make(chan []byte, 1024),
-		RemotePushChan: make(chan *remote.Msg, 1024),
+		ClientReadChan:   make(chan *MsgPack, 1024),
+		clients:          make(map[string]Connection),
+		users:            make(map[string]map[string]Connection),
+		routeMiddlewares: make(map[string][]Middleware),
+		handlers:         make(map[protocol.PackageType]EventHandler),
+		RemoteReadChan:   make(chan []byte, 1024),
+		RemotePushChan:   make(chan *remote.Msg, 1024),
+		dictionary:       protocol.NewDictBuilder(),
+		admission:        newAdmission(),
+		inflight:         newInflight(),
+		resume:           newResumeStore(),
+		drained:          make(chan struct{}),
+		drainTimeout:     defaultDrainTimeout,
+		handleTimeout:    defaultHandleTimeout,
+		heartbeat:        defaultHeartbeat,
+		heartbeatMissed:  defaultHeartbeatMissed,
+		handshakeTimeout: defaultHandshakeTimeout,
+		maxPacketSize:    defaultMaxPacketSize,
 	}
 }
 
 func (m *Manager) Run(addr string) {
 	// 设置不同的消息处理器
 	m.setupEventHandlers()
+	m.buildChain()
+	// 生成路由压缩字典
+	m.setupDictionary()
+	connectorConfig := game.Conf.GetConnector(m.ServerId)
+	if connectorConfig != nil {
+		m.compressThreshold = connectorConfig.CompressThreshold
+		m.allowOrigins = connectorConfig.AllowOrigins
+		if connectorConfig.HeartTime > 0 {
+			m.heartbeat = time.Duration(connectorConfig.HeartTime) * time.Second
+		}
+		if connectorConfig.HeartMaxMissed > 0 {
+			m.heartbeatMissed = connectorConfig.HeartMaxMissed
+		}
+		m.rateLimit = connectorConfig.RateLimit
+		m.writeQueueSize = connectorConfig.WriteQueueSize
+		m.slowPolicy = SlowPolicy(connectorConfig.SlowPolicy)
+		m.admission.setLimit(connectorConfig.MaxConnections, connectorConfig.MaxConnectionsPerIP)
+		m.resumeGrace = time.Duration(connectorConfig.ResumeGrace) * time.Second
+		if connectorConfig.DrainTimeout > 0 {
+			m.drainTimeout = time.Duration(connectorConfig.DrainTimeout) * time.Second
+		}
+		if connectorConfig.HandleTimeOut > 0 {
+			m.handleTimeout = time.Duration(connectorConfig.HandleTimeOut) * time.Second
+		}
+		if connectorConfig.MaxPacketSize > protocol.HeaderLen {
+			m.maxPacketSize = connectorConfig.MaxPacketSize
+		}
+		if connectorConfig.HandshakeTimeout > 0 {
+			m.handshakeTimeout = time.Duration(connectorConfig.HandshakeTimeout) * time.Second
+		}
+		// 配置了证书则开启tls
+		if connectorConfig.CertFile != "" && connectorConfig.KeyFile != "" {
+			loader, err := newCertLoader(connectorConfig.CertFile, connectorConfig.KeyFile)
+			if err != nil {
+				zap.L().Fatal("load connector cert err: ", zap.Error(err))
+			}
+			m.certLoader = loader
+		}
+	}
+	upgrader := websocketUpgrade
+	upgrader.CheckOrigin = m.checkOrigin
+	m.websocketUpgrade = &upgrader
 
 	go m.clientReadChanHandler()
 	go m.remoteReadChanHandler()
 	go m.remotePushChanHandler()
-	http.HandleFunc("/", m.serveWs)
+	go m.heartbeatCheck()
+	// 配置了tcp端口的同时监听tcp
+	if connectorConfig != nil && connectorConfig.TcpPort > 0 {
+		go m.serveTcp(fmt.Sprintf("%s:%d", connectorConfig.Host, connectorConfig.TcpPort))
+	}
+	if connectorConfig != nil && connectorConfig.AdminAddr != "" {
+		go m.serveAdmin(connectorConfig.AdminAddr)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", m.serveWs)
+	m.server = &http.Server{
+		Addr:    addr,
+		Handler: mux,
+	}
 
-	err := http.ListenAndServe(addr, nil)
-	if err != nil {
+	var err error
+	if m.certLoader != nil {
+		m.server.TLSConfig = m.certLoader.tlsConfig()
+		err = m.server.ListenAndServeTLS("", "")
+	} else {
+		err = m.server.ListenAndServe()
+	}
+	// drain时关闭监听
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		zap.L().Fatal("connector listen serve err: ", zap.Error(err))
 	}
 }
 
+// 优先使用自定义的校验，其次校验配置的白名单，都没有配置时允许所有来源
+func (m *Manager) checkOrigin(r *http.Request) bool {
+	if m.CheckOriginHandler != nil {
+		return m.CheckOriginHandler(r)
+	}
+	if len(m.allowOrigins) == 0 {
+		return true
+	}
+	// 原生客户端不会携带Origin
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	for _, allow := range m.allowOrigins {
+		if allow == "*" || strings.EqualFold(allow, origin) || (err == nil && strings.EqualFold(allow, u.Host)) {
+			return true
+		}
+	}
+	return false
+}
+
 func (m *Manager) serveWs(w http.ResponseWriter, r *http.Request) {
-	if m.websocketUpgrade == nil {
-		m.websocketUpgrade = &websocketUpgrade
+	if m.draining.Load() {
+		http.Error(w, "connector draining", http.StatusServiceUnavailable)
+		return
+	}
+	ip := remoteIP(r.RemoteAddr)
+	// 超过连接数限制时在升级之前拒绝
+	if err := m.admission.acquire(ip); err != nil {
+		m.Stats.Rejected.Add(1)
+		zap.L().Sugar().Warnf("reject websocket connection from %s: %v", ip, err)
+		status := http.StatusServiceUnavailable
+		if errors.Is(err, ErrTooManyConnectionsPerIP) {
+			status = http.StatusTooManyRequests
+		}
+		w.Header().Set("Retry-After", "5")
+		http.Error(w, err.Error(), status)
+		return
 	}
 	// http 服务升级为 websocket
 	wsConn, err := m.websocketUpgrade.Upgrade(w, r, nil)
 	if err != nil {
-		zap.L().Fatal("websocket upgrade failed, err: ", zap.Error(err))
+		// Origin校验不通过时也会升级失败，不能退出进程
+		m.admission.release(ip)
+		m.Stats.UpgradeFailed.Add(1)
+		zap.L().Error("websocket upgrade failed, err: ", zap.Error(err))
+		return
 	}
 	client := NewWsConnection(wsConn, m)
 	m.addClient(client)
 	client.Run()
 }
 
-func (m *Manager) addClient(client *WsConnection) {
+func (m *Manager) serveTcp(addr string) {
+	listener, err := gonet.Listen("tcp", addr)
+	if err != nil {
+		zap.L().Fatal("connector tcp listen err: ", zap.Error(err))
+	}
+	if m.certLoader != nil {
+		listener = tls.NewListener(listener, m.certLoader.tlsConfig())
+	}
+	m.Lock()
+	m.tcpListener = listener
+	m.Unlock()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, gonet.ErrClosed) {
+				return
+			}
+			zap.L().Error("tcp accept err: ", zap.Error(err))
+			continue
+		}
+		ip := remoteIP(conn.RemoteAddr().String())
+		if err := m.admission.acquire(ip); err != nil {
+			m.Stats.Rejected.Add(1)
+			zap.L().Sugar().Warnf("reject tcp connection from %s: %v", ip, err)
+			_ = conn.Close()
+			continue
+		}
+		client := NewTcpConnection(conn, m)
+		m.addClient(client)
+		client.Run()
+	}
+}
+
+func (m *Manager) addClient(client Connection) {
 	m.Lock()
 	defer m.Unlock()
-	m.clients[client.Cid] = client
+	m.clients[client.GetSession().Cid] = client
+	// 规定时间内没有完成握手的连接直接断开
+	time.AfterFunc(m.handshakeTimeout, func() {
+		if client.State() < StateAcked {
+			zap.L().Sugar().Infof("client[%s] handshake timeout", client.GetSession().Cid)
+			client.Close()
+		}
+	})
+}
+
+func (m *Manager) removeClient(client Connection) {
+	m.Lock()
+	// 已登录的连接非正常断开（踢出、drain时状态已经是Closing）才保留session等待恢复
+	parkable := client.State() == StateAuthenticated
+	client.SetState(StateClosing)
+	client.Close()
+	cid := client.GetSession().Cid
+	_, ok := m.clients[cid]
+	delete(m.clients, cid)
+	m.unbindUid(client)
+	m.Unlock()
+	if ok {
+		m.admission.release(client.RemoteIP())
+		m.inflight.drop(cid)
+	}
+	// 被顶替的连接由新连接接管，不需要通知下线
+	if ok && client.GetSession().Uid != "" && !client.GetSession().isTakenOver() {
+		session := client.GetSession()
+		if parkable && m.resumeGrace > 0 && session.issuedResumeToken() != "" && !m.draining.Load() {
+			// 宽限期内没有重连才通知node节点用户下线
+			m.resume.park(session, m.resumeGrace, m.offline)
+			return
+		}
+		m.offline(session)
+	}
+}
+
+// 通知node节点用户下线
+func (m *Manager) offline(session *Session) {
+	m.presenceLogout(session)
+	m.notifyServers(remote.OfflineType, session)
+}
+
+// 通知node节点断线的用户已经重连
+func (m *Manager) resumed(session *Session) {
+	m.notifyServers(remote.ResumeType, session)
+}
+
+func (m *Manager) notifyServers(msgType int, session *Session) {
+	if m.RemoteCli == nil {
+		return
+	}
+	msg := &remote.Msg{
+		Type:        msgType,
+		Cid:         session.Cid,
+		Uid:         session.Uid,
+		Src:         m.ServerId,
+		SessionData: session.GetData(),
+	}
+	for _, v := range game.Conf.ServersConf.Servers {
+		msg.Dst = v.ID
+		data, _ := json.Marshal(msg)
+		if err := m.RemoteCli.SendMsg(v.ID, data); err != nil {
+			zap.L().Sugar().Errorf("send msg type %d to %s err: %v", msgType, v.ID, err)
+		}
+	}
 }
 
-func (m *Manager) removeClient(wc *WsConnection) {
+// bindUid 连接登录后加入uid索引
+func (m *Manager) bindUid(c Connection) {
 	m.Lock()
 	defer m.Unlock()
-	wc.Close()
-	delete(m.clients, wc.Cid)
+	m.addUid(c)
+}
+
+// addUid 需要持有锁
+func (m *Manager) addUid(c Connection) {
+	session := c.GetSession()
+	// 已经断开的连接不再加入
+	if _, ok := m.clients[session.Cid]; !ok {
+		return
+	}
+	conns, ok := m.users[session.Uid]
+	if !ok {
+		conns = make(map[string]Connection, 1)
+		m.users[session.Uid] = conns
+	}
+	conns[session.Cid] = c
+}
+
+// unbindUid 需要持有锁
+func (m *Manager) unbindUid(c Connection) {
+	session := c.GetSession()
+	conns, ok := m.users[session.Uid]
+	if !ok {
+		return
+	}
+	delete(conns, session.Cid)
+	if len(conns) == 0 {
+		delete(m.users, session.Uid)
+	}
+}
+
+func (m *Manager) heartbeatTimeout() time.Duration {
+	return m.heartbeat * time.Duration(m.heartbeatMissed)
+}
+
+// 定时检查连接，连续 heartbeatMissed 个心跳周期没有收到任何数据包的连接直接断开
+func (m *Manager) heartbeatCheck() {
+	ticker := time.NewTicker(m.heartbeat)
+	defer ticker.Stop()
+	for range ticker.C {
+		timeout := m.heartbeatTimeout()
+		expired := make([]Connection, 0)
+		m.RLock()
+		for _, v := range m.clients {
+			if time.Since(v.LastActive()) > timeout {
+				expired = append(expired, v)
+			}
+		}
+		m.RUnlock()
+		for _, v := range expired {
+			zap.L().Sugar().Infof("client[%s] heartbeat timeout, uid=%s", v.GetSession().Cid, v.GetSession().Uid)
+			v.Close()
+		}
+	}
 }
 
 func (m *Manager) Close() {
@@ -124,12 +430,21 @@ func (m *Manager) clientReadChanHandler() {
 // 解析协议
 func (m *Manager) decodeClientPack(data *MsgPack) {
 	// zap.L().Info("receiver message " + string(data.Body))
-	packet, err := protocol.Decode(data.Body)
+	m.RLock()
+	conn, ok := m.clients[data.Cid]
+	m.RUnlock()
+	if !ok {
+		zap.L().Error("decode message err: no client found, cid=" + data.Cid)
+		return
+	}
+	// 心跳包和数据包都表示连接存活
+	conn.Touch()
+	packet, err := protocol.Decode(data.Body, conn.GetSession().Dictionary())
 	if err != nil {
 		zap.L().Error("decode message err: ", zap.Error(err))
 		return
 	}
-	if err = m.routeEvent(packet, data.Cid); err != nil {
+	if err = m.routeEvent(packet, conn); err != nil {
 		zap.L().Error("routeEvent err: ", zap.Error(err))
 	}
 }
@@ -143,23 +458,86 @@ func (m *Manager) setupEventHandlers() {
 }
 
 func (m *Manager) HandshakeHandler(packet *protocol.Packet, c Connection) error {
+	body := packet.HandshakeBody()
+	// 根据客户端的 Sys.Serializer 选择序列化方式，不支持的统一降级为json
+	// protobuf没有注册任何路由的消息时所有消息体都是json，不下发protobuf
+	sz, ok := serializer.Get(body.Sys.Serializer)
+	if pb, isPb := sz.(*serializer.ProtobufSerializer); !ok || isPb && pb.Empty() {
+		sz = serializer.Default()
+	}
+	c.GetSession().SetSerializer(sz)
+	// 客户端请求并且服务端配置了阈值才开启下行压缩
+	compress := m.compressThreshold > 0 && body.Sys.Compress
+	c.GetSession().SetCompress(compress)
+	// 路由字典由服务端下发，忽略客户端上报的字典
+	dict := m.dictionary.Dictionary()
+	c.GetSession().SetDictionary(dict)
+	// 带了恢复令牌的连接接回断线前的session，绑定uid之前的push仍然缓存在resume中
+	parked := m.resume.take(body.Sys.ResumeToken)
+	var resumeToken string
+	if parked != nil {
+		resumeToken = c.GetSession().resume(parked.session)
+		zap.L().Sugar().Infof("client[%s] resume session of uid=%s", c.GetSession().Cid, c.GetSession().Uid)
+	}
 	res := protocol.HandshakeResponse{
 		Code: 200,
 		Sys: protocol.Sys{
-			Heartbeat: 3,
+			Heartbeat:   uint8(m.heartbeat / time.Second),
+			Serializer:  sz.Name(),
+			Dict:        dict.Dict(),
+			Compress:    compress,
+			ResumeToken: resumeToken,
+			Resumed:     parked != nil,
 		},
 	}
 	data, _ := json.Marshal(res)
 	buf, err := protocol.Encode(packet.Type, data)
 	if err != nil {
 		zap.L().Error("encode packet err: ", zap.Error(err))
+		if parked != nil {
+			m.resume.finish(parked)
+		}
 		return err
 	}
-	return c.SendMessage(buf)
+	c.SetState(StateHandshaked)
+	if parked == nil {
+		return c.SendMessage(buf)
+	}
+	m.replay(c, buf, parked)
+	// 连接变了，更新在线记录
+	m.presenceLogin(c.GetSession())
+	m.resumed(c.GetSession())
+	return nil
+}
+
+// replay 恢复session的连接先发送握手回复和断线期间缓存的push，再绑定uid接收新的push。
+// 持有锁时 Response 不能缓存或者发送push，所以push不会丢失也不会乱序
+func (m *Manager) replay(c Connection, handshake []byte, parked *parkedSession) {
+	m.Lock()
+	defer m.Unlock()
+	if err := c.SendMessage(handshake); err != nil {
+		m.resume.finish(parked)
+		return
+	}
+	for _, message := range m.resume.finish(parked) {
+		res, err := m.encodeMessage(c.GetSession(), message)
+		if err != nil {
+			zap.L().Sugar().Errorf("resume push encode err:%v", err)
+			continue
+		}
+		_ = c.SendMessage(res)
+	}
+	m.addUid(c)
 }
 
 func (m *Manager) HandshakeAckHandler(packet *protocol.Packet, c Connection) error {
 	zap.L().Info("receiver handshake ack message...")
+	// 恢复了session的连接不需要重新登录
+	if c.GetSession().Uid != "" {
+		c.SetState(StateAuthenticated)
+		return nil
+	}
+	c.SetState(StateAcked)
 	return nil
 }
 
@@ -180,42 +558,64 @@ func (m *Manager) MessageHandler(packet *protocol.Packet, c Connection) error {
 	message := packet.MessageBody()
 	zap.L().Sugar().Infof("receiver message body, type=%v, router=%v, data:%v",
 		message.Type, message.Route, string(message.Data))
+	// drain开始后不再处理新请求，已经转发的请求由 inflight 等待回复
+	if m.draining.Load() {
+		return m.rejectDraining(c, message)
+	}
 	// connector.entryHandler.entry
 	routeStr := message.Route
 	routers := strings.Split(routeStr, ".")
 	if len(routers) != 3 {
-		return errors.New("router unsupported")
+		return m.responseError(c, message, errors.New("router unsupported"))
+	}
+	body, err := c.GetSession().DecodeBody(message.Route, message.Data)
+	if err != nil {
+		zap.L().Error("decode message body err: ", zap.Error(err))
+		return m.responseError(c, message, err)
 	}
+	if !c.Limiter().AllowRoute(routeStr, body) {
+		return m.rateLimited(c, message)
+	}
+	message.Data = body
 	serverType := routers[0]
 	handlerMethod := fmt.Sprintf("%s.%s", routers[1], routers[2])
 	connectorConfig := game.Conf.GetConnectorByServerType(serverType)
 	if connectorConfig != nil { // connectorConfig = "connector"
 		// 本地connector服务器处理
-		handler, ok := m.ConnectorHandlers[handlerMethod]
-		if ok {
-			data, err := handler(c.GetSession(), message.Data)
-			if err != nil {
-				return err
-			}
-			marshal, _ := json.Marshal(data)
-			message.Type = protocol.Response
-			message.Data = marshal
-			encode, err := protocol.MessageEncode(message)
-			if err != nil {
-				return err
-			}
-			res, err := protocol.Encode(packet.Type, encode)
-			if err != nil {
-				return err
-			}
-			return c.SendMessage(res)
+		handler, ok := m.chain[handlerMethod]
+		if !ok {
+			return m.responseError(c, message, errors.New("no handler found"))
+		}
+		uid := c.GetSession().Uid
+		data, err := m.call(handler, c.GetSession(), message.Data)
+		// 已登录的连接不能切换用户，uid索引、在线记录和session数据都属于原来的用户
+		if c.State() == StateAuthenticated && c.GetSession().Uid != uid {
+			c.GetSession().SetUid(uid)
+			return m.responseError(c, message, errs.AlreadyLoggedIn)
+		}
+		if err != nil {
+			return m.responseError(c, message, err)
+		}
+		// handler绑定了uid代表登录成功
+		if c.State() == StateAcked && c.GetSession().Uid != "" {
+			c.SetState(StateAuthenticated)
+			m.bindUid(c)
+			m.takeover(c)
+		}
+		marshal, _ := json.Marshal(data)
+		message.Type = protocol.Response
+		message.Data = marshal
+		res, err := m.encodeMessage(c.GetSession(), message)
+		if err != nil {
+			return err
 		}
+		return c.SendMessage(res)
 	} else {
 		// nats 远端调用处理 hall.userHandler.updateUserAddress
 		dst, err := m.selectDst(serverType)
 		if err != nil {
 			zap.L().Error("remote send msg selectDst err: ", zap.Error(err))
-			return err
+			return m.responseError(c, message, err)
 		}
 		msg := &remote.Msg{
 			Cid:         c.GetSession().Cid,
@@ -224,35 +624,160 @@ func (m *Manager) MessageHandler(packet *protocol.Packet, c Connection) error {
 			Dst:         dst,
 			Router:      handlerMethod,
 			Body:        message,
-			SessionData: c.GetSession().data, // 一个map[string]any
+			SessionData: c.GetSession().GetData(), // 一个map[string]any
+			TraceId:     remote.NewTraceId(),
 		}
 		data, _ := json.Marshal(msg)
+		// 记录等待回复的请求，drain时等待回复之后再断开连接
+		if message.Type == protocol.Request {
+			m.inflight.add(msg.Cid)
+		}
 		err = m.RemoteCli.SendMsg(dst, data)
 		if err != nil {
-			zap.L().Error("remote send msg err：", zap.Error(err))
-			return err
+			zap.L().Error("remote send msg err：", zap.String("traceId", msg.TraceId), zap.Error(err))
+			if message.Type == protocol.Request {
+				m.inflight.done(msg.Cid)
+			}
+			return m.responseError(c, message, err)
 		}
 	}
 	return nil
 }
 
+// 连接级别限流，在读协程中调用，超限的数据包不进入 ClientReadChan
+func (m *Manager) allowClientPack(conn Connection, body []byte) bool {
+	if len(body) == 0 || protocol.PackageType(body[0]) != protocol.Data || conn.Limiter().Allow() {
+		return true
+	}
+	packet, err := protocol.Decode(body, conn.GetSession().Dictionary())
+	if err != nil {
+		return false
+	}
+	if err := m.rateLimited(conn, packet.MessageBody()); err != nil {
+		zap.L().Sugar().Infof("client[%s] %v", conn.GetSession().Cid, err)
+	}
+	return false
+}
+
+// drain期间收到的请求回复错误，并再次推送迁移目标给客户端
+func (m *Manager) rejectDraining(c Connection, message *protocol.Message) error {
+	if target := m.migrateTarget(); target != nil {
+		res, err := m.encodeMessage(c.GetSession(), &protocol.Message{
+			Type:  protocol.Push,
+			Route: MigrateRoute,
+			Data:  target,
+		})
+		if err == nil {
+			_ = c.SendMessage(res)
+		}
+	}
+	return m.responseError(c, message, errs.ServerDraining)
+}
+
+// 超限时回复错误响应，一个统计周期内多次超限直接踢出
+func (m *Manager) rateLimited(c Connection, message *protocol.Message) error {
+	m.Stats.RateLimited.Add(1)
+	if c.Limiter().Violate() {
+		m.Kick(c.GetSession().Cid, protocol.KickReason{
+			Code:   protocol.KickRateLimit,
+			Reason: errs.TooFrequent.Error(),
+		})
+		return errs.TooFrequent
+	}
+	return m.responseError(c, message, errs.TooFrequent)
+}
+
+// 给客户端回复带 ErrorMask 的错误响应，消息体为错误码和错误信息，notify 没有响应只返回错误
+func (m *Manager) responseError(c Connection, message *protocol.Message, e error) error {
+	if message.Type != protocol.Request {
+		return e
+	}
+	data, _ := json.Marshal(errs.Wrap(e).Body())
+	res := &protocol.Message{
+		Type:  protocol.Response,
+		ID:    message.ID,
+		Route: message.Route,
+		Data:  data,
+		Error: true,
+	}
+	buf, err := m.encodeMessage(c.GetSession(), res)
+	if err != nil {
+		zap.L().Error("encode error response err: ", zap.Error(err))
+		return e
+	}
+	if err := c.SendMessage(buf); err != nil {
+		zap.L().Error("send error response err: ", zap.Error(err))
+	}
+	return e
+}
+
 func (m *Manager) KickHandler(packet *protocol.Packet, c Connection) error {
 	zap.L().Info("receiver kick  message...")
 	return nil
 }
 
-func (m *Manager) routeEvent(packet *protocol.Packet, cid string) error {
+// Kick 服务端主动踢出连接，id可以是cid也可以是uid，发送踢出原因后关闭连接
+func (m *Manager) Kick(id string, reason protocol.KickReason) {
+	conns := make([]Connection, 0, 1)
+	m.RLock()
+	if conn, ok := m.clients[id]; ok {
+		conns = append(conns, conn)
+	} else {
+		for _, v := range m.users[id] {
+			conns = append(conns, v)
+		}
+	}
+	m.RUnlock()
+	if len(conns) == 0 {
+		return
+	}
+	data, _ := json.Marshal(reason)
+	buf, err := protocol.Encode(protocol.Kick, data)
+	if err != nil {
+		zap.L().Error("encode kick packet err: ", zap.Error(err))
+		return
+	}
+	for _, conn := range conns {
+		conn.SetState(StateClosing)
+		zap.L().Sugar().Infof("kick client[%s], uid=%s, reason=%v", conn.GetSession().Cid, conn.GetSession().Uid, reason)
+		_ = conn.SendAndClose(buf)
+	}
+}
+
+func (m *Manager) routeEvent(packet *protocol.Packet, conn Connection) error {
+	if !acceptPacket(conn.State(), packet.Type) {
+		if conn.State() == StateClosing {
+			return nil
+		}
+		m.Kick(conn.GetSession().Cid, protocol.KickReason{
+			Code:   protocol.KickProtocolError,
+			Reason: fmt.Sprintf("unexpected packet type %d", packet.Type),
+		})
+		return fmt.Errorf("packet type %d not allowed in state %d", packet.Type, conn.State())
+	}
 	// 根据packet.type来做不同的处理  处理器
-	conn, ok := m.clients[cid]
+	handler, ok := m.handlers[packet.Type]
 	if ok {
-		handler, ok := m.handlers[packet.Type]
-		if ok {
-			return handler(packet, conn)
-		} else {
-			return errors.New("no packetType found")
-		}
+		return handler(packet, conn)
+	}
+	return errors.New("no packetType found")
+}
+
+// 判断当前连接状态下是否可以处理该类型的数据包
+func acceptPacket(state ConnState, packageType protocol.PackageType) bool {
+	switch packageType {
+	case protocol.Handshake:
+		return state == StateConnected
+	case protocol.HandshakeAck:
+		return state == StateHandshaked
+	case protocol.Heartbeat:
+		return state >= StateHandshaked && state < StateClosing
+	case protocol.Data:
+		return state >= StateAcked && state < StateClosing
+	case protocol.Kick:
+		return state < StateClosing
 	}
-	return errors.New("no client found")
+	return false
 }
 
 // 读取node节点通过 nats 推送的消息
@@ -271,6 +796,26 @@ func (m *Manager) remoteReadChanHandler() {
 			continue
 		}
 
+		// node节点请求踢出用户
+		if msg.Type == remote.KickType {
+			if msg.Kick != nil {
+				m.Kick(utils.Default(msg.Uid, msg.Cid), *msg.Kick)
+			}
+			continue
+		}
+
+		// 用户在其他connector重新登录
+		if msg.Type == remote.TakeoverType {
+			m.handOver(msg)
+			continue
+		}
+
+		// node节点回复的路由信息，加入路由压缩字典
+		if msg.Type == remote.RoutesType {
+			m.addRemoteRoutes(msg)
+			continue
+		}
+
 		if msg.Body != nil {
 			if msg.Body.Type == protocol.Request || msg.Body.Type == protocol.Response {
 				// 给客户端回信息 都是 response
@@ -316,31 +861,133 @@ func (m *Manager) setSessionData(msg remote.Msg) {
 }
 
 func (m *Manager) Response(msg *remote.Msg) {
-	conn, ok := m.clients[msg.Cid]
+	if msg.Body.Type == protocol.Response {
+		m.inflight.done(msg.Cid)
+	}
+	// push发给PushUser，和触发push的连接是否还在无关
+	if msg.Body.Type == protocol.Push {
+		encoded := make(map[encodeKey][]byte)
+		// 持有锁缓存，和 replay 互斥，push要么在补发的消息中，要么在绑定uid之后直接发送
+		m.RLock()
+		defer m.RUnlock()
+		// 断线等待恢复的用户先缓存起来，重连后补发
+		for _, uid := range msg.PushUser {
+			m.resume.buffer(uid, msg.Body)
+		}
+		for _, uid := range msg.PushUser {
+			for _, v := range m.users[uid] {
+				m.push(v, msg.Body, encoded)
+			}
+		}
+	} else {
+		m.RLock()
+		conn, ok := m.clients[msg.Cid]
+		m.RUnlock()
+		if !ok {
+			zap.L().Sugar().Infof("%s client down, uid = %s", msg.Cid, msg.Uid)
+			return
+		}
+		res, err := m.encodeMessage(conn.GetSession(), msg.Body)
+		if err != nil {
+			zap.L().Sugar().Errorf("Response encode err:%v", err)
+			return
+		}
+		conn.SendMessage(res)
+	}
+}
+
+// 同一种序列化方式、路由字典和压缩方式只编码一次
+type encodeKey struct {
+	serializer string
+	dict       *protocol.Dictionary
+	compress   bool
+}
+
+func (m *Manager) push(v Connection, message *protocol.Message, encoded map[encodeKey][]byte) {
+	key := encodeKey{
+		serializer: v.GetSession().Serializer().Name(),
+		dict:       v.GetSession().Dictionary(),
+		compress:   v.GetSession().Compress(),
+	}
+	res, ok := encoded[key]
 	if !ok {
-		zap.L().Sugar().Info("%s client down, uid = %s", msg.Cid, msg.Uid)
-		return
+		var err error
+		res, err = m.encodeMessage(v.GetSession(), message)
+		if err != nil {
+			zap.L().Sugar().Errorf("Response push encode err:%v", err)
+			return
+		}
+		encoded[key] = res
 	}
+	_ = v.SendMessage(res)
+}
 
-	buf, err := protocol.MessageEncode(msg.Body)
+// 按照连接协商的序列化方式、路由字典和压缩方式编码消息
+func (m *Manager) encodeMessage(session *Session, message *protocol.Message) ([]byte, error) {
+	route := message.Route
+	if message.Error {
+		route = "" // 错误响应的消息体 {code, msg} 不按路由的消息类型转换
+	}
+	body, err := session.EncodeBody(route, message.Data)
 	if err != nil {
-		zap.L().Sugar().Error("Response MessageEncode err:%v", zap.Error(err))
-		return
+		return nil, err
 	}
-
-	res, err := protocol.Encode(protocol.Data, buf)
+	encodeMsg := *message
+	encodeMsg.Compressed = false
+	if session.Compress() && len(body) >= m.compressThreshold {
+		deflated, err := protocol.DeflateData(body)
+		if err != nil {
+			zap.L().Error("deflate message body err: ", zap.Error(err))
+		} else if len(deflated) < len(body) {
+			m.Stats.CompressedMessages.Add(1)
+			m.Stats.CompressRawBytes.Add(int64(len(body)))
+			m.Stats.CompressSavedBytes.Add(int64(len(body) - len(deflated)))
+			body = deflated
+			encodeMsg.Compressed = true
+		}
+	}
+	encodeMsg.Data = body
+	buf, err := protocol.MessageEncode(&encodeMsg, session.Dictionary())
 	if err != nil {
-		zap.L().Sugar().Error("Response Encode err:%v", zap.Error(err))
-		return
+		return nil, err
 	}
+	return protocol.Encode(protocol.Data, buf)
+}
 
-	if msg.Body.Type == protocol.Push {
-		for _, v := range m.clients {
-			if utils.Contains(msg.PushUser, v.GetSession().Uid) {
-				v.SendMessage(res)
-			}
+// 本地connector的路由直接加入字典，node节点的路由通过nats查询
+func (m *Manager) setupDictionary() {
+	if connectorConfig := game.Conf.GetConnector(m.ServerId); connectorConfig != nil {
+		routes := make([]string, 0, len(m.ConnectorHandlers))
+		for handlerMethod := range m.ConnectorHandlers {
+			routes = append(routes, fmt.Sprintf("%s.%s", connectorConfig.ServerType, handlerMethod))
 		}
-	} else {
-		conn.SendMessage(res)
+		m.dictionary.Add(routes...)
+	}
+	if m.RemoteCli == nil {
+		return
+	}
+	for _, v := range game.Conf.ServersConf.Servers {
+		msg := &remote.Msg{
+			Type: remote.RoutesType,
+			Src:  m.ServerId,
+			Dst:  v.ID,
+		}
+		data, _ := json.Marshal(msg)
+		if err := m.RemoteCli.SendMsg(v.ID, data); err != nil {
+			zap.L().Error("query node routes err: ", zap.Error(err))
+		}
+	}
+}
+
+func (m *Manager) addRemoteRoutes(msg remote.Msg) {
+	serverConfig := game.Conf.GetServer(msg.Src)
+	if serverConfig == nil {
+		zap.L().Error("add remote routes err: no server found, serverId=" + msg.Src)
+		return
+	}
+	routes := make([]string, 0, len(msg.Routes))
+	for _, handlerMethod := range msg.Routes {
+		routes = append(routes, fmt.Sprintf("%s.%s", serverConfig.ServerType, handlerMethod))
 	}
+	m.dictionary.Add(routes...)
 }
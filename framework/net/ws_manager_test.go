@@ -0,0 +1,79 @@
+package net
+
+import (
+	"common/utils"
+	"fmt"
+	"framework/protocol"
+	"framework/remote"
+	"testing"
+	"time"
+)
+
+type benchConnection struct {
+	session *Session
+	state   ConnState
+}
+
+func (c *benchConnection) Close()                        {}
+func (c *benchConnection) SendMessage(buf []byte) error  { return nil }
+func (c *benchConnection) SendAndClose(buf []byte) error { return nil }
+func (c *benchConnection) GetSession() *Session          { return c.session }
+func (c *benchConnection) Touch()                        {}
+func (c *benchConnection) LastActive() time.Time         { return time.Time{} }
+func (c *benchConnection) State() ConnState              { return c.state }
+func (c *benchConnection) SetState(state ConnState)      { c.state = state }
+func (c *benchConnection) Limiter() *RateLimiter         { return nil }
+func (c *benchConnection) RemoteIP() string              { return "127.0.0.1" }
+
+const benchConnections = 50000
+
+func newBenchManager() (*Manager, *remote.Msg) {
+	m := NewManager()
+	m.dictionary.Add("ServerMessagePush")
+	dict := m.dictionary.Dictionary()
+	for i := 0; i < benchConnections; i++ {
+		session := NewSession(fmt.Sprintf("cid-%d", i))
+		session.Uid = fmt.Sprintf("uid-%d", i)
+		session.SetDictionary(dict)
+		c := &benchConnection{
+			session: session,
+			state:   StateAuthenticated,
+		}
+		m.clients[session.Cid] = c
+		m.bindUid(c)
+	}
+	msg := &remote.Msg{
+		PushUser: []string{"uid-1", "uid-5000", "uid-10000", "uid-20000", "uid-30000", "uid-49999"},
+		Body: &protocol.Message{
+			Type:  protocol.Push,
+			Route: "ServerMessagePush",
+			Data:  []byte(`{"roomID":"336842","pushRouter":"UpdateUserInfoPush"}`),
+		},
+	}
+	return m, msg
+}
+
+// BenchmarkResponsePush 50000个在线连接时给一个房间的6个用户推送消息，按uid索引查找连接
+func BenchmarkResponsePush(b *testing.B) {
+	m, msg := newBenchManager()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.Response(msg)
+	}
+}
+
+// BenchmarkResponsePushScan 对比基准，建立uid索引之前遍历所有连接查找push的用户
+func BenchmarkResponsePushScan(b *testing.B) {
+	m, msg := newBenchManager()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		encoded := make(map[encodeKey][]byte)
+		m.RLock()
+		for _, v := range m.clients {
+			if utils.Contains(msg.PushUser, v.GetSession().Uid) {
+				m.push(v, msg.Body, encoded)
+			}
+		}
+		m.RUnlock()
+	}
+}
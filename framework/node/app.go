@@ -1,17 +1,37 @@
 package node
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	errs "framework/err"
+	"framework/game"
 	"framework/remote"
+	"time"
 
 	"go.uber.org/zap"
 )
 
 type App struct {
-	remoteCli remote.Client
-	readChan  chan []byte
-	writeChan chan *remote.Msg
-	handlers  LogicHandler
+	serverId    string
+	remoteCli   remote.Client
+	readChan    chan []byte
+	writeChan   chan *remote.Msg
+	handlers    LogicHandler
+	rpcHandlers RPCHandler // 其他节点调用的rpc路由
+	offline     OfflineHandler
+	resume      ResumeHandler
+	presence    remote.Presence
+	channels    remote.ChannelStore // 多个节点共享的频道，例如联盟、全服公告
+	local       remote.ChannelStore // 只在本节点的频道，例如房间
+	// 中间件
+	middlewares      []Middleware
+	routeMiddlewares map[string][]Middleware
+	chain            LogicHandler // 包装了中间件的handler，Run时生成
+	dispatcher       *dispatcher
+	handleTimeout    time.Duration // 0不限制
+	rpcTimeout       time.Duration
+	Stats            Stats
 }
 
 func Default() *App {
@@ -19,17 +39,40 @@ func Default() *App {
 		readChan:  make(chan []byte, 1024),
 		writeChan: make(chan *remote.Msg, 1024),
 		handlers:  make(LogicHandler),
+		channels:  remote.NewMemoryChannelStore(),
+		local:     remote.NewMemoryChannelStore(),
+
+		routeMiddlewares: make(map[string][]Middleware),
 	}
 }
 
 func (a *App) Run(serverId string) error {
+	a.serverId = serverId
+	a.buildChain()
+	maxRunRoutine := 0
+	if conf := game.Conf.GetServer(serverId); conf != nil {
+		maxRunRoutine = conf.MaxRunRoutineNum
+		a.handleTimeout = time.Duration(conf.HandleTimeOut) * time.Second
+		a.rpcTimeout = time.Duration(conf.RPCTimeOut) * time.Second
+	}
+	a.dispatcher = newDispatcher(maxRunRoutine, a.handleMsg, func(delta int64) {
+		a.Stats.Running.Add(delta)
+	})
 	a.remoteCli = remote.NewNatsClient(serverId, a.readChan)
 	err := a.remoteCli.Run()
 	if err != nil {
 		return err
 	}
+	// 没有注册的rpc路由也要订阅，调用方才能收到 errs.RPCRouteNotFound
+	if err := a.remoteCli.ServeRPC(a.serveRPC); err != nil {
+		return err
+	}
 	go a.readChanMsg()
 	go a.writeChanMsg()
+	// 通知所有connector本节点的路由，用于生成路由压缩字典
+	for _, v := range game.Conf.ServersConf.Connector {
+		a.writeChan <- a.routesMsg(v.ID)
+	}
 	return nil
 }
 
@@ -40,30 +83,138 @@ func (a *App) readChanMsg() {
 			continue
 		}
 
-		session := remote.NewSession(a.remoteCli, &remoteMsg)
-		session.SetData(remoteMsg.SessionData)
+		if remoteMsg.Type == remote.RoutesType {
+			a.writeChan <- a.routesMsg(remoteMsg.Src)
+			continue
+		}
+		if remoteMsg.Type != remote.OfflineType && remoteMsg.Type != remote.ResumeType && remoteMsg.Body == nil {
+			continue
+		}
+		a.dispatcher.dispatch(dispatchKey(&remoteMsg), &remoteMsg)
+	}
+}
+
+// handleMsg 在dispatcher的worker中执行
+func (a *App) handleMsg(remoteMsg *remote.Msg) {
+	switch remoteMsg.Type {
+	case remote.OfflineType:
+		a.userOffline(remoteMsg)
+	case remote.ResumeType:
+		a.userResume(remoteMsg)
+	default:
+		a.handleRequest(remoteMsg)
+	}
+}
+
+// handleRequest 超过HandleTimeOut先回复超时错误并取消ctx，handler返回后才处理同一个key的下一条消息。
+// 为了保证同一个key的顺序，超时后worker仍然等待handler返回，耗时的handler必须检查ctx.Done()尽快返回
+func (a *App) handleRequest(remoteMsg *remote.Msg) {
+	handlerFunc := a.chain[remoteMsg.Router]
+	if handlerFunc == nil {
+		a.response(remoteMsg, errors.New("no handler found"))
+		return
+	}
+	session := a.newSession(remoteMsg)
+	session.SetData(remoteMsg.SessionData)
+	ctx, cancel := a.handleContext(remoteMsg)
+	defer cancel()
+	session.SetContext(ctx)
 
-		router := remoteMsg.Router
-		if handlerFunc := a.handlers[router]; handlerFunc != nil {
-			result := handlerFunc(session, remoteMsg.Body.Data)
+	// 在goroutine启动前取出请求数据，超时回复不会和handler同时访问remoteMsg
+	payload := remoteMsg.Body.Data
+	done := make(chan any, 1)
+	go func() {
+		done <- a.call(handlerFunc, session, payload)
+	}()
+	select {
+	case result := <-done:
+		a.response(remoteMsg, result)
+	case <-ctx.Done():
+		a.Stats.Timeouts.Add(1)
+		zap.L().Sugar().Warnf("handler %s timeout, uid=%s traceId=%s", remoteMsg.Router, remoteMsg.Uid, remote.TraceId(ctx))
+		a.response(remoteMsg, errs.HandleTimeout)
+		<-done
+	}
+}
 
-			message := remoteMsg.Body
-			if result != nil {
-				body, _ := json.Marshal(result)
-				message.Data = body
-			}
+// handleContext 带有请求元数据的ctx，connector没有生成traceId时（下线、重连通知）在这里生成
+func (a *App) handleContext(remoteMsg *remote.Msg) (context.Context, context.CancelFunc) {
+	return a.newContext(remote.Metadata{
+		TraceId: remoteMsg.TraceId,
+		Uid:     remoteMsg.Uid,
+		Cid:     remoteMsg.Cid,
+		Src:     remoteMsg.Src,
+	})
+}
 
-			responseMsg := &remote.Msg{
-				Src:  remoteMsg.Dst,
-				Dst:  remoteMsg.Src,
-				Body: message,
-				Uid:  remoteMsg.Uid,
-				Cid:  remoteMsg.Cid,
-			}
+// newContext ctx在HandleTimeOut后取消
+func (a *App) newContext(md remote.Metadata) (context.Context, context.CancelFunc) {
+	if md.TraceId == "" {
+		md.TraceId = remote.NewTraceId()
+	}
+	ctx := remote.NewContext(context.Background(), md)
+	if a.handleTimeout > 0 {
+		return context.WithTimeout(ctx, a.handleTimeout)
+	}
+	return context.WithCancel(ctx)
+}
 
-			a.writeChan <- responseMsg
-		}
+func (a *App) newSession(remoteMsg *remote.Msg) *remote.Session {
+	session := remote.NewSession(a.remoteCli, remoteMsg)
+	if a.presence != nil {
+		session.SetPresence(a.presence)
 	}
+	session.SetChannelStore(a.channels)
+	session.SetLocalChannelStore(a.local)
+	return session
+}
+
+func (a *App) userOffline(remoteMsg *remote.Msg) {
+	if a.offline == nil {
+		return
+	}
+	session := a.newSession(remoteMsg)
+	session.SetData(remoteMsg.SessionData)
+	ctx, cancel := a.handleContext(remoteMsg)
+	defer cancel()
+	session.SetContext(ctx)
+	a.protect("offline", session, a.offline)
+}
+
+func (a *App) userResume(remoteMsg *remote.Msg) {
+	if a.resume == nil {
+		return
+	}
+	session := a.newSession(remoteMsg)
+	session.SetData(remoteMsg.SessionData)
+	ctx, cancel := a.handleContext(remoteMsg)
+	defer cancel()
+	session.SetContext(ctx)
+	a.protect("resume", session, a.resume)
+}
+
+// 将handler的处理结果回复给connector，结果为error时回复带错误标识的响应
+func (a *App) response(remoteMsg *remote.Msg, result any) {
+	// 在副本上生成回复，不修改请求的消息体
+	message := *remoteMsg.Body
+	if e, ok := result.(error); ok {
+		body, _ := json.Marshal(errs.Wrap(e).Body())
+		message.Data = body
+		message.Error = true
+	} else if result != nil {
+		body, _ := json.Marshal(result)
+		message.Data = body
+	}
+
+	responseMsg := &remote.Msg{
+		Src:  remoteMsg.Dst,
+		Dst:  remoteMsg.Src,
+		Body: &message,
+		Uid:  remoteMsg.Uid,
+		Cid:  remoteMsg.Cid,
+	}
+
+	a.writeChan <- responseMsg
 }
 
 func (a *App) writeChanMsg() {
@@ -75,6 +226,19 @@ func (a *App) writeChanMsg() {
 	}
 }
 
+func (a *App) routesMsg(dst string) *remote.Msg {
+	routes := make([]string, 0, len(a.handlers))
+	for router := range a.handlers {
+		routes = append(routes, router)
+	}
+	return &remote.Msg{
+		Type:   remote.RoutesType,
+		Src:    a.serverId,
+		Dst:    dst,
+		Routes: routes,
+	}
+}
+
 func (a *App) Close() {
 	if a.remoteCli != nil {
 		a.remoteCli.Close()
@@ -84,3 +248,22 @@ func (a *App) Close() {
 func (a *App) RegisterHandler(handler LogicHandler) {
 	a.handlers = handler
 }
+
+func (a *App) RegisterOfflineHandler(handler OfflineHandler) {
+	a.offline = handler
+}
+
+// SetPresence 设置共享的在线记录，push时发给用户所在的connector
+func (a *App) SetPresence(presence remote.Presence) {
+	a.presence = presence
+}
+
+// SetChannelStore 设置 Session.Channel 的存储，默认只在本节点内存中，多个节点共享频道时设置redis的实现
+// Session.LocalChannel 始终使用本节点内存
+func (a *App) SetChannelStore(store remote.ChannelStore) {
+	a.channels = store
+}
+
+func (a *App) RegisterResumeHandler(handler ResumeHandler) {
+	a.resume = handler
+}
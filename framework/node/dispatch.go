@@ -0,0 +1,96 @@
+package node
+
+import (
+	"fmt"
+	"framework/remote"
+	"sync"
+)
+
+const defaultMaxRunRoutine = 1024
+
+// dispatcher 同一个key的消息按到达顺序依次处理，不同key的消息并发处理
+type dispatcher struct {
+	mu      sync.Mutex
+	queues  map[string][]*remote.Msg // 正在处理的key和等待处理的消息
+	workers chan struct{}            // 限制同时运行的worker数
+	handle  func(msg *remote.Msg)
+	running func(delta int64)
+}
+
+func newDispatcher(maxWorkers int, handle func(msg *remote.Msg), running func(delta int64)) *dispatcher {
+	if maxWorkers <= 0 {
+		maxWorkers = defaultMaxRunRoutine
+	}
+	return &dispatcher{
+		queues:  make(map[string][]*remote.Msg),
+		workers: make(chan struct{}, maxWorkers),
+		handle:  handle,
+		running: running,
+	}
+}
+
+// dispatch key已经有worker在处理时排到队尾，否则启动一个worker
+// worker数达到上限时阻塞，不再从nats读取消息
+func (d *dispatcher) dispatch(key string, msg *remote.Msg) {
+	d.mu.Lock()
+	if queue, ok := d.queues[key]; ok {
+		d.queues[key] = append(queue, msg)
+		d.mu.Unlock()
+		return
+	}
+	d.queues[key] = nil
+	d.mu.Unlock()
+
+	d.workers <- struct{}{}
+	go d.run(key, msg)
+}
+
+// spawn 不需要排序的任务（rpc请求）也占用一个worker，worker数达到上限时阻塞
+func (d *dispatcher) spawn(fn func()) {
+	d.workers <- struct{}{}
+	go func() {
+		d.running(1)
+		defer func() {
+			d.running(-1)
+			<-d.workers
+		}()
+		fn()
+	}()
+}
+
+// run 处理完key的所有消息后退出
+func (d *dispatcher) run(key string, msg *remote.Msg) {
+	d.running(1)
+	defer func() {
+		d.running(-1)
+		<-d.workers
+	}()
+	for {
+		d.handle(msg)
+		d.mu.Lock()
+		queue := d.queues[key]
+		if len(queue) == 0 {
+			delete(d.queues, key)
+			d.mu.Unlock()
+			return
+		}
+		msg = queue[0]
+		queue[0] = nil
+		d.queues[key] = queue[1:]
+		d.mu.Unlock()
+	}
+}
+
+// dispatchKey 在房间中时按房间排序，否则按用户排序
+// 只保证同一个key的消息顺序，不同key的handler会并发执行，例如不同用户的创建、加入房间，
+// handler共享的状态（联盟、房间列表、房间成员）需要自己加锁
+func dispatchKey(msg *remote.Msg) string {
+	if roomId, ok := msg.SessionData["roomId"]; ok && roomId != nil && roomId != "" {
+		return fmt.Sprintf("room:%v", roomId)
+	}
+	if msg.Uid != "" {
+		return "uid:" + msg.Uid
+	}
+	// 没有登录的连接
+	return "cid:" + msg.Src + "." + msg.Cid
+}
@@ -1,6 +1,27 @@
 package node
 
-import "framework/remote"
+import (
+	"context"
+	"framework/remote"
+)
 
+// HandlerFunc 返回 error 时给客户端回复错误响应
+// 超过HandleTimeOut后 session.Context() 被取消，handler返回之前同一个key的下一条消息不会处理，耗时操作需要使用该ctx
 type HandlerFunc func(session *remote.Session, msg []byte) any
+
+// ContextHandlerFunc 带ctx的handler，ctx在HandleTimeOut后取消，可以用 remote.FromContext 取出请求的元数据
+type ContextHandlerFunc func(ctx context.Context, session *remote.Session, msg []byte) any
 type LogicHandler map[string]HandlerFunc
+
+// ContextHandler 转换成 HandlerFunc 注册到 LogicHandler 中，中间件对两种handler都生效
+func ContextHandler(handler ContextHandlerFunc) HandlerFunc {
+	return func(session *remote.Session, msg []byte) any {
+		return handler(session.Context(), session, msg)
+	}
+}
+
+// OfflineHandler 用户连接断开时connector通知node节点
+type OfflineHandler func(session *remote.Session)
+
+// ResumeHandler 断线的用户在宽限期内重连时connector通知node节点，之前不会收到 OfflineHandler
+type ResumeHandler func(session *remote.Session)
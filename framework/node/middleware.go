@@ -0,0 +1,108 @@
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"framework/remote"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Middleware 包装 HandlerFunc，可以在handler前后执行逻辑或者直接返回结果
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// Use 注册全局中间件，对所有路由生效，需要在Run之前调用
+func (a *App) Use(middlewares ...Middleware) {
+	a.middlewares = append(a.middlewares, middlewares...)
+}
+
+// UseRoute 注册路由中间件，route和 RegisterHandler 的key一致，例如 gameHandler.roomMessageNotify
+func (a *App) UseRoute(route string, middlewares ...Middleware) {
+	a.routeMiddlewares[route] = append(a.routeMiddlewares[route], middlewares...)
+}
+
+// 全局中间件在外层，路由中间件在内层，同一层按照注册顺序从外到内执行
+func (a *App) buildChain() {
+	a.chain = make(LogicHandler, len(a.handlers))
+	for route, handler := range a.handlers {
+		middlewares := append(append([]Middleware{}, a.middlewares...), a.routeMiddlewares[route]...)
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			handler = middlewares[i](handler)
+		}
+		a.chain[route] = handler
+	}
+}
+
+// Auth 没有登录的session直接返回err
+func Auth(err error) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(session *remote.Session, msg []byte) any {
+			if session.GetUid() == "" {
+				return err
+			}
+			return next(session, msg)
+		}
+	}
+}
+
+// Logging 记录请求和返回的错误
+func Logging() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(session *remote.Session, msg []byte) any {
+			traceId := remote.TraceId(session.Context())
+			zap.L().Sugar().Infof("handle %s uid=%s traceId=%s msg=%s", session.GetRouter(), session.GetUid(), traceId, string(msg))
+			result := next(session, msg)
+			if e, ok := result.(error); ok {
+				zap.L().Sugar().Warnf("handle %s uid=%s traceId=%s err: %v", session.GetRouter(), session.GetUid(), traceId, e)
+			}
+			return result
+		}
+	}
+}
+
+// Timing 处理时间超过slow时记录日志
+func Timing(slow time.Duration) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(session *remote.Session, msg []byte) any {
+			start := time.Now()
+			result := next(session, msg)
+			if cost := time.Since(start); cost > slow {
+				zap.L().Sugar().Warnf("slow handler %s uid=%s cost=%v", session.GetRouter(), session.GetUid(), cost)
+			}
+			return result
+		}
+	}
+}
+
+// Validator 请求参数实现该接口时由 Validate 中间件校验
+type Validator interface {
+	Validate() error
+}
+
+type requestKey struct{}
+
+// Validate 请求参数不能解析为T或者校验不通过时返回err，解析后的参数放到session的ctx中，handler通过 Request 获取
+func Validate[T any](err error) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(session *remote.Session, msg []byte) any {
+			req := new(T)
+			if e := json.Unmarshal(msg, req); e != nil {
+				return err
+			}
+			if v, ok := any(req).(Validator); ok {
+				if e := v.Validate(); e != nil {
+					return err
+				}
+			}
+			session.SetContext(context.WithValue(session.Context(), requestKey{}, req))
+			return next(session, msg)
+		}
+	}
+}
+
+// Request 返回 Validate[T] 解析的请求参数，路由没有注册 Validate[T] 时返回false
+func Request[T any](session *remote.Session) (*T, bool) {
+	req, ok := session.Context().Value(requestKey{}).(*T)
+	return req, ok
+}
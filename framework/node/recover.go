@@ -0,0 +1,56 @@
+package node
+
+import (
+	"errors"
+	errs "framework/err"
+	"framework/remote"
+	"runtime/debug"
+
+	"go.uber.org/zap"
+)
+
+// errPanic Recover 中间件捕获panic后返回的错误，错误码和 errs.InternalError 相同，call 据此统计panic次数
+var errPanic = errs.NewError(errs.InternalError.Code, errors.New(errs.InternalError.Error()))
+
+// Recover handler和内层中间件panic时记录堆栈并返回错误，注册在 Logging 之后，Logging 可以记录返回的错误
+// 没有注册时由 call 兜底
+func Recover() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(session *remote.Session, msg []byte) (result any) {
+			defer func() {
+				if r := recover(); r != nil {
+					zap.L().Sugar().Errorf("handler %s panic, uid=%s traceId=%s: %v\n%s", session.GetRouter(), session.GetUid(), remote.TraceId(session.Context()), r, debug.Stack())
+					result = errPanic
+				}
+			}()
+			return next(session, msg)
+		}
+	}
+}
+
+// call 执行handler，panic时记录堆栈并返回错误，不影响同一节点上的其他消息
+func (a *App) call(handler HandlerFunc, session *remote.Session, msg []byte) (result any) {
+	defer func() {
+		if r := recover(); r != nil {
+			a.Stats.Panics.Add(1)
+			zap.L().Sugar().Errorf("handler %s panic, uid=%s: %v\n%s", session.GetRouter(), session.GetUid(), r, debug.Stack())
+			result = errs.InternalError
+		}
+	}()
+	result = handler(session, msg)
+	if result == errPanic {
+		a.Stats.Panics.Add(1)
+	}
+	return result
+}
+
+// protect 执行下线、重连等没有回复的回调
+func (a *App) protect(name string, session *remote.Session, fn func(session *remote.Session)) {
+	defer func() {
+		if r := recover(); r != nil {
+			a.Stats.Panics.Add(1)
+			zap.L().Sugar().Errorf("%s handler panic, uid=%s: %v\n%s", name, session.GetUid(), r, debug.Stack())
+		}
+	}()
+	fn(session)
+}
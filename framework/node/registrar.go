@@ -0,0 +1,102 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"framework/remote"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// Routes 按方法名覆盖默认的路由，值为完整的路由，例如 {"CreateRoom": "unionHandler.create"}，值为"-"时不注册该方法
+type Routes map[string]string
+
+// Registrar 通过反射注册handler结构体的方法，避免手写的路由和客户端不一致
+// 签名为 HandlerFunc 或 ContextHandlerFunc 的导出方法会被注册，路由为 结构体名.方法名（都转为lowerCamel）
+// 例如 *UnionHandler 的 CreateRoom 注册为 unionHandler.createRoom
+type Registrar struct {
+	handlers LogicHandler
+	owners   map[string]string // 路由由哪个方法注册，用于重复时的错误信息
+	errs     []error
+}
+
+func NewRegistrar() *Registrar {
+	return &Registrar{
+		handlers: make(LogicHandler),
+		owners:   make(map[string]string),
+	}
+}
+
+// Register 注册handler的方法，overrides中的方法名不存在或者路由重复时 Handlers 返回错误
+func (r *Registrar) Register(handler any, overrides ...Routes) *Registrar {
+	value := reflect.ValueOf(handler)
+	typ := reflect.Indirect(value).Type()
+	if typ.Name() == "" {
+		r.errs = append(r.errs, fmt.Errorf("register handler: %T is not a named type", handler))
+		return r
+	}
+	override := make(Routes)
+	for _, routes := range overrides {
+		for method, route := range routes {
+			override[method] = route
+		}
+	}
+	prefix := lowerCamel(typ.Name())
+	for i := 0; i < value.NumMethod(); i++ {
+		method := value.Type().Method(i).Name
+		handlerFunc := asHandlerFunc(value.Method(i).Interface())
+		if handlerFunc == nil {
+			continue
+		}
+		route, ok := override[method]
+		delete(override, method)
+		if !ok {
+			route = prefix + "." + lowerCamel(method)
+		}
+		if route == "-" {
+			continue
+		}
+		owner := typ.Name() + "." + method
+		if prev, ok := r.owners[route]; ok {
+			r.errs = append(r.errs, fmt.Errorf("register handler: duplicate route %s in %s and %s", route, prev, owner))
+			continue
+		}
+		r.owners[route] = owner
+		r.handlers[route] = handlerFunc
+	}
+	// 剩下的是没有对应handler方法的覆盖，一般是方法名写错了
+	for method := range override {
+		r.errs = append(r.errs, fmt.Errorf("register handler: %s has no handler method %s", typ.Name(), method))
+	}
+	return r
+}
+
+// Handlers 返回注册的路由，有错误时应该在启动时退出
+func (r *Registrar) Handlers() (LogicHandler, error) {
+	return r.handlers, errors.Join(r.errs...)
+}
+
+func asHandlerFunc(method any) HandlerFunc {
+	switch f := method.(type) {
+	case func(*remote.Session, []byte) any:
+		return f
+	case func(context.Context, *remote.Session, []byte) any:
+		return ContextHandler(f)
+	}
+	return nil
+}
+
+// lowerCamel 开头连续的大写字母转为小写，只保留下一个单词的首字母，例如 URLHandler 转为 urlHandler
+func lowerCamel(name string) string {
+	runes := []rune(name)
+	upper := 0
+	for upper < len(runes) && unicode.IsUpper(runes[upper]) {
+		upper++
+	}
+	if upper > 1 && upper < len(runes) {
+		upper--
+	}
+	return strings.ToLower(string(runes[:upper])) + string(runes[upper:])
+}
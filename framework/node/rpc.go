@@ -0,0 +1,60 @@
+package node
+
+import (
+	"context"
+	"encoding/json"
+	errs "framework/err"
+	"framework/remote"
+	"runtime/debug"
+
+	"go.uber.org/zap"
+)
+
+// RPCHandlerFunc 处理其他节点的rpc请求，payload为调用方传入的json，返回值序列化为json回复
+// ctx中带有调用方的traceId和uid，Src为调用方节点
+type RPCHandlerFunc func(ctx context.Context, payload []byte) (any, error)
+
+// RPCHandler rpc路由，只能由其他节点调用，与客户端的 LogicHandler 分开注册
+type RPCHandler map[string]RPCHandlerFunc
+
+func (a *App) RegisterRPCHandler(handlers RPCHandler) {
+	a.rpcHandlers = handlers
+}
+
+// Request 调用dst节点的rpc路由，超时时间为servers.json中的rpcTimeOut，ctx中的traceId、uid传给对方
+// 返回的错误为 errs.RPCTimeout、errs.RPCRouteNotFound、errs.RPCUnavailable 或者对方handler返回的错误
+func (a *App) Request(ctx context.Context, dst, route string, payload any) ([]byte, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return a.remoteCli.RequestContext(ctx, dst, route, data, a.rpcTimeout)
+}
+
+// serveRPC rpc请求和客户端消息共用 MaxRunRoutineNum 个worker
+func (a *App) serveRPC(req *remote.RPCRequest, reply func(data []byte, err error)) {
+	a.dispatcher.spawn(func() {
+		reply(a.handleRPC(req))
+	})
+}
+
+func (a *App) handleRPC(req *remote.RPCRequest) (data []byte, err error) {
+	handler, ok := a.rpcHandlers[req.Route]
+	if !ok {
+		return nil, errs.RPCRouteNotFound
+	}
+	ctx, cancel := a.newContext(req.Metadata)
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			a.Stats.Panics.Add(1)
+			zap.L().Sugar().Errorf("rpc handler %s panic, traceId=%s: %v\n%s", req.Route, remote.TraceId(ctx), r, debug.Stack())
+			data, err = nil, errs.InternalError
+		}
+	}()
+	result, err := handler(ctx, req.Payload)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(result)
+}
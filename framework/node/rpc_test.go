@@ -0,0 +1,96 @@
+package node
+
+import (
+	"context"
+	"errors"
+	errs "framework/err"
+	"framework/remote"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newRPCApp(maxWorkers int, handlers RPCHandler) *App {
+	a := Default()
+	a.handleTimeout = time.Second
+	a.dispatcher = newDispatcher(maxWorkers, a.handleMsg, func(delta int64) {
+		a.Stats.Running.Add(delta)
+	})
+	a.RegisterRPCHandler(handlers)
+	return a
+}
+
+func TestHandleRPC(t *testing.T) {
+	a := newRPCApp(1, RPCHandler{
+		"user.info": func(ctx context.Context, payload []byte) (any, error) {
+			md, _ := remote.FromContext(ctx)
+			if _, ok := ctx.Deadline(); !ok {
+				return nil, errors.New("no deadline")
+			}
+			return map[string]string{
+				"payload": string(payload),
+				"traceId": md.TraceId,
+				"uid":     md.Uid,
+				"src":     md.Src,
+			}, nil
+		},
+		"user.panic": func(ctx context.Context, payload []byte) (any, error) {
+			panic("boom")
+		},
+	})
+
+	data, err := a.handleRPC(&remote.RPCRequest{
+		Route:   "user.info",
+		Payload: []byte(`"1"`),
+		Metadata: remote.Metadata{
+			TraceId: "trace-1",
+			Uid:     "1001",
+			Src:     "game-001",
+		},
+	})
+	want := `{"payload":"\"1\"","src":"game-001","traceId":"trace-1","uid":"1001"}`
+	if err != nil || string(data) != want {
+		t.Fatalf("user.info = %s, %v, want %s", data, err, want)
+	}
+
+	if _, err := a.handleRPC(&remote.RPCRequest{Route: "user.none"}); err != errs.RPCRouteNotFound {
+		t.Fatalf("missing route err = %v, want RPCRouteNotFound", err)
+	}
+
+	if _, err := a.handleRPC(&remote.RPCRequest{Route: "user.panic"}); err != errs.InternalError {
+		t.Fatalf("panic err = %v, want InternalError", err)
+	}
+	if a.Stats.Panics.Load() != 1 {
+		t.Fatalf("panics = %d, want 1", a.Stats.Panics.Load())
+	}
+}
+
+func TestServeRPCWorkerLimit(t *testing.T) {
+	var running, peak atomic.Int64
+	a := newRPCApp(2, RPCHandler{
+		"slow": func(ctx context.Context, payload []byte) (any, error) {
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			running.Add(-1)
+			return nil, nil
+		},
+	})
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		a.serveRPC(&remote.RPCRequest{Route: "slow"}, func(data []byte, err error) {
+			wg.Done()
+		})
+	}
+	wg.Wait()
+	if peak.Load() > 2 {
+		t.Fatalf("peak running = %d, want <= 2", peak.Load())
+	}
+}
@@ -0,0 +1,10 @@
+package node
+
+import "sync/atomic"
+
+// Stats node节点运行时的统计指标
+type Stats struct {
+	Panics   atomic.Int64 // handler发生panic的次数
+	Timeouts atomic.Int64 // handler超过HandleTimeOut的次数
+	Running  atomic.Int64 // 正在处理消息的worker数
+}
@@ -0,0 +1,52 @@
+package protocol
+
+import (
+	"bytes"
+	"errors"
+)
+
+var ErrInvalidPacket = errors.New("invalid packet")
+
+// Decoder 按照 Encode 的4字节包头从字节流中切分数据包，处理粘包和半包
+type Decoder struct {
+	buf     bytes.Buffer
+	maxSize int // 单个数据包消息体的最大长度
+}
+
+func NewDecoder() *Decoder {
+	return NewLimitDecoder(MaxPacketSize)
+}
+
+// NewLimitDecoder 消息体超过maxSize的数据包返回 ErrInvalidPacket，不会继续缓存
+func NewLimitDecoder(maxSize int) *Decoder {
+	if maxSize <= 0 || maxSize > MaxPacketSize {
+		maxSize = MaxPacketSize
+	}
+	return &Decoder{
+		maxSize: maxSize,
+	}
+}
+
+// Decode 写入新读到的数据，返回其中所有完整的数据包（包含包头），不完整的部分留到下次
+func (d *Decoder) Decode(data []byte) ([][]byte, error) {
+	d.buf.Write(data)
+	packets := make([][]byte, 0)
+	for d.buf.Len() >= HeaderLen {
+		header := d.buf.Bytes()[:HeaderLen]
+		packageType := PackageType(header[0])
+		if packageType < Handshake || packageType > Kick {
+			return nil, ErrInvalidPacket
+		}
+		size := BytesToInt(header[1:HeaderLen])
+		if size > d.maxSize {
+			return nil, ErrInvalidPacket
+		}
+		if d.buf.Len() < HeaderLen+size {
+			break
+		}
+		packet := make([]byte, HeaderLen+size)
+		_, _ = d.buf.Read(packet)
+		packets = append(packets, packet)
+	}
+	return packets, nil
+}
@@ -0,0 +1,102 @@
+package protocol
+
+import (
+	"bytes"
+	"testing"
+)
+
+func mustEncode(t *testing.T, packageType PackageType, body string) []byte {
+	t.Helper()
+	packet, err := Encode(packageType, []byte(body))
+	if err != nil {
+		t.Fatalf("encode err: %v", err)
+	}
+	return packet
+}
+
+// TestDecoder 按照每次读到的数据切分，检查每次 Decode 返回的数据包
+func TestDecoder(t *testing.T) {
+	data := mustEncode(t, Data, `{"route":"entryHandler.entry"}`)
+	heartbeat := mustEncode(t, Heartbeat, "")
+	oversize := mustEncode(t, Data, string(bytes.Repeat([]byte("a"), 65)))
+
+	tests := []struct {
+		name    string
+		maxSize int
+		reads   [][]byte
+		want    [][][]byte // 每次读取后返回的数据包
+		wantErr bool
+	}{
+		{
+			name:  "one packet in one read",
+			reads: [][]byte{data},
+			want:  [][][]byte{{data}},
+		},
+		{
+			name:  "packet split in body",
+			reads: [][]byte{data[:10], data[10:20], data[20:]},
+			want:  [][][]byte{{}, {}, {data}},
+		},
+		{
+			name:  "packet split in header",
+			reads: [][]byte{data[:2], data[2:]},
+			want:  [][][]byte{{}, {data}},
+		},
+		{
+			name:  "several packets in one read",
+			reads: [][]byte{concat(heartbeat, data, heartbeat)},
+			want:  [][][]byte{{heartbeat, data, heartbeat}},
+		},
+		{
+			name:  "packets and a half in one read",
+			reads: [][]byte{concat(data, heartbeat, data[:5]), data[5:]},
+			want:  [][][]byte{{data, heartbeat}, {data}},
+		},
+		{
+			name:    "packet over max size",
+			maxSize: 64,
+			reads:   [][]byte{oversize[:HeaderLen]},
+			wantErr: true,
+		},
+		{
+			name:    "packet at max size",
+			maxSize: 65,
+			reads:   [][]byte{oversize},
+			want:    [][][]byte{{oversize}},
+		},
+		{
+			name:    "invalid package type",
+			reads:   [][]byte{{0, 0, 0, 0}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewLimitDecoder(tt.maxSize)
+			for i, read := range tt.reads {
+				packets, err := d.Decode(read)
+				if err != nil {
+					if !tt.wantErr || i != len(tt.reads)-1 {
+						t.Fatalf("read %d err: %v", i, err)
+					}
+					return
+				}
+				if len(packets) != len(tt.want[i]) {
+					t.Fatalf("read %d got %d packets, want %d", i, len(packets), len(tt.want[i]))
+				}
+				for j, packet := range packets {
+					if !bytes.Equal(packet, tt.want[i][j]) {
+						t.Fatalf("read %d packet %d = %v, want %v", i, j, packet, tt.want[i][j])
+					}
+				}
+			}
+			if tt.wantErr {
+				t.Fatalf("want err, got nil")
+			}
+		})
+	}
+}
+
+func concat(packets ...[]byte) []byte {
+	return bytes.Join(packets, nil)
+}
@@ -0,0 +1,106 @@
+package protocol
+
+import (
+	"maps"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Dictionary 路由压缩字典，创建之后只读，可以被多个连接并发使用
+type Dictionary struct {
+	routes map[string]uint16 // 路由信息映射为uint16
+	codes  map[uint16]string // uint16映射为路由信息
+}
+
+func NewDictionary(dict map[string]uint16) *Dictionary {
+	d := &Dictionary{
+		routes: make(map[string]uint16, len(dict)),
+		codes:  make(map[uint16]string, len(dict)),
+	}
+	for route, code := range dict {
+		r := strings.TrimSpace(route)
+		d.routes[r] = code
+		d.codes[code] = r
+	}
+	return d
+}
+
+// GetCode 获取路由对应的压缩码，字典为nil时不压缩
+func (d *Dictionary) GetCode(route string) (uint16, bool) {
+	if d == nil {
+		return 0, false
+	}
+	code, ok := d.routes[route]
+	return code, ok
+}
+
+func (d *Dictionary) GetRoute(code uint16) (string, bool) {
+	if d == nil {
+		return "", false
+	}
+	route, ok := d.codes[code]
+	return route, ok
+}
+
+// Dict 返回握手时下发给客户端的字典
+func (d *Dictionary) Dict() map[string]uint16 {
+	if d == nil {
+		return nil
+	}
+	return maps.Clone(d.routes)
+}
+
+// DictBuilder 服务端维护的路由字典，路由只增不减，已分配的压缩码保持不变
+type DictBuilder struct {
+	sync.RWMutex
+	routes   map[string]uint16
+	next     uint16
+	snapshot *Dictionary
+}
+
+func NewDictBuilder() *DictBuilder {
+	return &DictBuilder{
+		routes: make(map[string]uint16),
+		next:   1,
+	}
+}
+
+// Add 添加路由，重复的路由忽略
+func (b *DictBuilder) Add(routes ...string) {
+	b.Lock()
+	defer b.Unlock()
+	sorted := make([]string, 0, len(routes))
+	for _, route := range routes {
+		r := strings.TrimSpace(route)
+		if _, ok := b.routes[r]; ok || r == "" {
+			continue
+		}
+		sorted = append(sorted, r)
+	}
+	sort.Strings(sorted)
+	for _, r := range sorted {
+		if _, ok := b.routes[r]; ok {
+			continue
+		}
+		b.routes[r] = b.next
+		b.next++
+		b.snapshot = nil
+	}
+}
+
+// Dictionary 获取当前字典的快照
+func (b *DictBuilder) Dictionary() *Dictionary {
+	b.RLock()
+	snapshot := b.snapshot
+	b.RUnlock()
+	if snapshot != nil {
+		return snapshot
+	}
+	b.Lock()
+	defer b.Unlock()
+	if b.snapshot == nil {
+		b.snapshot = NewDictionary(b.routes)
+	}
+	return b.snapshot
+}
@@ -0,0 +1,17 @@
+package protocol
+
+type KickCode int
+
+const (
+	KickServer         KickCode = 1 // 服务端主动踢出
+	KickBanned         KickCode = 2 // 账号被封禁
+	KickDuplicateLogin KickCode = 3 // 账号在别处登录
+	KickProtocolError  KickCode = 4 // 数据包顺序错误，例如未握手就发送数据
+	KickRateLimit      KickCode = 5 // 多次请求过于频繁
+)
+
+// KickReason Kick数据包的消息体
+type KickReason struct {
+	Code   KickCode `json:"code"`
+	Reason string   `json:"reason"`
+}
@@ -7,14 +7,6 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
-	"strings"
-
-	"go.uber.org/zap"
-)
-
-var (
-	routes = make(map[string]uint16) // 路由信息映射为uint16
-	codes  = make(map[uint16]string) // uint16映射为路由信息
 )
 
 type PackageType byte
@@ -54,7 +46,8 @@ type Packet struct {
 	Body any
 }
 
-func Decode(payload []byte) (*Packet, error) {
+// Decode 解析数据包，Data类型的路由使用该连接的字典解压
+func Decode(payload []byte, dict *Dictionary) (*Packet, error) {
 	if len(payload) < HeaderLen {
 		return nil, errors.New("data len invalid")
 	}
@@ -67,13 +60,10 @@ func Decode(payload []byte) (*Packet, error) {
 		if err != nil {
 			return nil, err
 		}
-		if body.Sys.Dict != nil {
-			SetDictionary(body.Sys.Dict)
-		}
 		p.Body = body
 	}
 	if p.Type == Data {
-		m, err := MessageDecode(payload[HeaderLen:])
+		m, err := MessageDecode(payload[HeaderLen:], dict)
 		if err != nil {
 			return nil, err
 		}
@@ -82,40 +72,22 @@ func Decode(payload []byte) (*Packet, error) {
 	return p, nil
 }
 
-func SetDictionary(dict map[string]uint16) {
-	if dict == nil {
-		return
-	}
-
-	for route, code := range dict {
-		r := strings.TrimSpace(route) // 去掉开头结尾的空格
-		// duplication check
-		if _, ok := routes[r]; ok {
-			zap.L().Sugar().Error("duplicated route(route: %s, code: %d)", r, code)
-			return
-		}
-
-		if _, ok := codes[code]; ok {
-			zap.L().Sugar().Error("duplicated route(route: %s, code: %d)", r, code)
-			return
-		}
-
-		// update map, using last value when key duplicated
-		routes[r] = code
-		codes[code] = r
-	}
-}
-
-func MessageEncode(m *Message) ([]byte, error) {
+func MessageEncode(m *Message, dict *Dictionary) ([]byte, error) {
 	if m.Type < Request || m.Type > Push {
 		return nil, errors.New("invalid message type")
 	}
 	buf := make([]byte, 0)
 	flag := byte(m.Type) << 1
-	code, compressed := routes[m.Route]
+	code, compressed := dict.GetCode(m.Route)
 	if compressed {
 		flag |= RouteCompressMask
 	}
+	if m.Compressed {
+		flag |= GZIPMask
+	}
+	if m.Error {
+		flag |= ErrorMask
+	}
 	buf = append(buf, flag)
 	if m.Type == Request || m.Type == Response {
 		n := m.ID
@@ -168,7 +140,7 @@ func routable(t MessageType) bool {
 // | response |----010-|<message id>        |
 // | push     |----011-|<route>             |
 // ------------------------------------------
-func MessageDecode(body []byte) (Message, error) {
+func MessageDecode(body []byte, dict *Dictionary) (Message, error) {
 	m := Message{}
 	flag := body[0]
 	m.Type = MessageType((flag >> 1) & TypeMask)
@@ -200,7 +172,7 @@ func MessageDecode(body []byte) (Message, error) {
 		if flag&RouteCompressMask == 1 {
 			m.routeCompressed = true
 			code := binary.BigEndian.Uint16(body[offset:(offset + 2)])
-			route, found := GetRoute(code)
+			route, found := dict.GetRoute(code)
 			if !found {
 				return m, errors.New("route info not found in dictionary")
 			}
@@ -221,6 +193,7 @@ func MessageDecode(body []byte) (Message, error) {
 	m.Data = body[offset:]
 	var err error
 	if flag&GZIPMask == GZIPMask {
+		m.Compressed = true
 		m.Data, err = InflateData(m.Data)
 		if err != nil {
 			return m, err
@@ -229,11 +202,6 @@ func MessageDecode(body []byte) (Message, error) {
 	return m, nil
 }
 
-func GetRoute(code uint16) (route string, found bool) {
-	route, found = codes[code]
-	return route, found
-}
-
 func InflateData(data []byte) ([]byte, error) {
 	zr, err := zlib.NewReader(bytes.NewBuffer(data))
 	if err != nil {
@@ -243,6 +211,18 @@ func InflateData(data []byte) ([]byte, error) {
 	return io.ReadAll(zr)
 }
 
+func DeflateData(data []byte) ([]byte, error) {
+	var bb bytes.Buffer
+	zw := zlib.NewWriter(&bb)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return bb.Bytes(), nil
+}
+
 func Encode(packageType PackageType, body []byte) ([]byte, error) {
 	if packageType == None {
 		return nil, errors.New("encode unsupported packageType")
@@ -285,6 +265,9 @@ type Sys struct {
 	Heartbeat    uint8             `json:"heartbeat"`
 	Dict         map[string]uint16 `json:"dict"`
 	Serializer   string            `json:"serializer"`
+	Compress     bool              `json:"compress"`              // 客户端请求开启下行压缩，服务端回复是否开启
+	ResumeToken  string            `json:"resumeToken,omitempty"` // 客户端带上断线前的恢复令牌，恢复成功时服务端回复新的令牌
+	Resumed      bool              `json:"resumed,omitempty"`     // 服务端回复是否恢复了断线前的session
 }
 
 type HandshakeResponse struct {
@@ -299,4 +282,5 @@ type Message struct {
 	Data            []byte      // payload  消息体的原始数据
 	routeCompressed bool        // is route Compressed 是否启用路由压缩
 	Error           bool        // response error
+	Compressed      bool        // data compressed 消息体是否经过zlib压缩
 }
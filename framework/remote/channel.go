@@ -0,0 +1,159 @@
+package remote
+
+import (
+	"common/utils"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const channelTimeout = 3 * time.Second
+
+var ErrNoChannelStore = errors.New("channel store not set")
+
+// ChannelStore 频道成员的存储，由node节点注入到session中。
+// NewMemoryChannelStore 只在本节点可见，多个节点共享频道（例如联盟、全服公告）时使用基于redis的实现，
+// 房间等只在一个节点上的频道使用 Session.LocalChannel，不要放到redis中
+type ChannelStore interface {
+	Add(ctx context.Context, name string, uids ...string) error
+	Remove(ctx context.Context, name string, uids ...string) error
+	Members(ctx context.Context, name string) ([]string, error)
+	Destroy(ctx context.Context, name string) error
+}
+
+type memoryChannelStore struct {
+	sync.RWMutex
+	channels map[string]map[string]struct{}
+}
+
+func NewMemoryChannelStore() ChannelStore {
+	return &memoryChannelStore{
+		channels: make(map[string]map[string]struct{}),
+	}
+}
+
+func (m *memoryChannelStore) Add(ctx context.Context, name string, uids ...string) error {
+	m.Lock()
+	defer m.Unlock()
+	members, ok := m.channels[name]
+	if !ok {
+		members = make(map[string]struct{}, len(uids))
+		m.channels[name] = members
+	}
+	for _, uid := range uids {
+		members[uid] = struct{}{}
+	}
+	return nil
+}
+
+func (m *memoryChannelStore) Remove(ctx context.Context, name string, uids ...string) error {
+	m.Lock()
+	defer m.Unlock()
+	members, ok := m.channels[name]
+	if !ok {
+		return nil
+	}
+	for _, uid := range uids {
+		delete(members, uid)
+	}
+	if len(members) == 0 {
+		delete(m.channels, name)
+	}
+	return nil
+}
+
+func (m *memoryChannelStore) Members(ctx context.Context, name string) ([]string, error) {
+	m.RLock()
+	defer m.RUnlock()
+	members := make([]string, 0, len(m.channels[name]))
+	for uid := range m.channels[name] {
+		members = append(members, uid)
+	}
+	return members, nil
+}
+
+func (m *memoryChannelStore) Destroy(ctx context.Context, name string) error {
+	m.Lock()
+	defer m.Unlock()
+	delete(m.channels, name)
+	return nil
+}
+
+// Channel 按名字广播的用户分组，例如房间、联盟、全服公告
+type Channel struct {
+	name    string
+	session *Session
+	local   bool
+}
+
+func (c *Channel) Name() string {
+	return c.name
+}
+
+func (c *Channel) Add(uids ...string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), channelTimeout)
+	defer cancel()
+	store := c.session.channelStore(c.local)
+	if store == nil {
+		return ErrNoChannelStore
+	}
+	return store.Add(ctx, c.name, uids...)
+}
+
+func (c *Channel) Remove(uids ...string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), channelTimeout)
+	defer cancel()
+	store := c.session.channelStore(c.local)
+	if store == nil {
+		return ErrNoChannelStore
+	}
+	return store.Remove(ctx, c.name, uids...)
+}
+
+func (c *Channel) Members() ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), channelTimeout)
+	defer cancel()
+	store := c.session.channelStore(c.local)
+	if store == nil {
+		return nil, ErrNoChannelStore
+	}
+	return store.Members(ctx, c.name)
+}
+
+func (c *Channel) Destroy() error {
+	ctx, cancel := context.WithTimeout(context.Background(), channelTimeout)
+	defer cancel()
+	store := c.session.channelStore(c.local)
+	if store == nil {
+		return ErrNoChannelStore
+	}
+	return store.Destroy(ctx, c.name)
+}
+
+// Push 推送给频道内的所有用户，查询成员失败时记录日志并返回错误，不会推送
+func (c *Channel) Push(data any, router string) error {
+	return c.PushExcept(data, router)
+}
+
+// PushExcept 推送给频道内除了except之外的用户
+func (c *Channel) PushExcept(data any, router string, except ...string) error {
+	members, err := c.Members()
+	if err != nil {
+		zap.L().Sugar().Errorf("channel[%s] push %s members err:%v", c.name, router, err)
+		return err
+	}
+	users := make([]string, 0, len(members))
+	for _, uid := range members {
+		if !utils.Contains(except, uid) {
+			users = append(users, uid)
+		}
+	}
+	if len(users) == 0 {
+		return nil
+	}
+	c.session.Push(users, data, router)
+	return nil
+}
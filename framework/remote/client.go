@@ -1,7 +1,15 @@
 package remote
 
+import (
+	"context"
+	"time"
+)
+
 type Client interface {
 	Run() error
 	SendMsg(string, []byte) error
+	Request(dst, route string, payload []byte, timeout time.Duration) ([]byte, error)
+	RequestContext(ctx context.Context, dst, route string, payload []byte, timeout time.Duration) ([]byte, error)
+	ServeRPC(handler RPCServeFunc) error
 	Close() error
 }
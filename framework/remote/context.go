@@ -0,0 +1,36 @@
+package remote
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Metadata 一次请求的元数据，由node节点放入handler的ctx
+type Metadata struct {
+	TraceId string // connector收到客户端请求时生成，日志中用来串联一次请求
+	Uid     string
+	Cid     string
+	Src     string // 转发请求的connector
+}
+
+type metadataKey struct{}
+
+func NewContext(ctx context.Context, md Metadata) context.Context {
+	return context.WithValue(ctx, metadataKey{}, md)
+}
+
+func FromContext(ctx context.Context) (Metadata, bool) {
+	md, ok := ctx.Value(metadataKey{}).(Metadata)
+	return md, ok
+}
+
+// TraceId ctx中没有元数据时返回空字符串
+func TraceId(ctx context.Context) string {
+	md, _ := FromContext(ctx)
+	return md.TraceId
+}
+
+func NewTraceId() string {
+	return uuid.NewString()
+}
@@ -5,13 +5,23 @@ import "framework/protocol"
 type Msg struct {
 	Cid         string
 	Uid         string
-	Type        int // 0 normal 1 session
+	Type        int // 0 normal 1 session 2 routes 3 kick 4 offline 5 resume 6 takeover
 	Src         string
 	Dst         string
 	Router      string
 	Body        *protocol.Message
 	SessionData map[string]any
 	PushUser    []string
+	Routes      []string
+	Kick        *protocol.KickReason
+	TraceId     string
 }
 
-const SessionType = 1
+const (
+	SessionType  = 1
+	RoutesType   = 2 // connector向node查询路由，node回复自己注册的路由用于生成路由压缩字典
+	KickType     = 3 // node请求connector踢出用户
+	OfflineType  = 4 // connector通知node用户连接断开
+	ResumeType   = 5 // connector通知node断线的用户在宽限期内重连回来了
+	TakeoverType = 6 // 用户在其他connector重新登录，通知旧的connector踢出连接并回复session数据
+)
@@ -0,0 +1,16 @@
+package remote
+
+import "context"
+
+// Presence 多个connector共享的在线记录，记录uid当前登录在哪个connector的哪个连接上。
+// connector在登录和下线时更新，node节点push时按照记录把用户分组发给各自的connector
+type Presence interface {
+	// Login 记录uid登录到serverId的cid连接，返回之前的记录，没有时返回空字符串
+	Login(ctx context.Context, uid, serverId, cid string) (prevServerId, prevCid string, err error)
+	// Logout 只有记录仍然是serverId的cid连接时才删除，避免删除其他connector上的新登录
+	Logout(ctx context.Context, uid, serverId, cid string) error
+	// Get 查询uid所在的connector和连接
+	Get(ctx context.Context, uid string) (serverId, cid string, err error)
+	// Locate 批量查询uid所在的connector，不在线的uid不返回
+	Locate(ctx context.Context, uids []string) (map[string]string, error)
+}
@@ -0,0 +1,111 @@
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	errs "framework/err"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+const defaultRPCTimeout = 5 * time.Second
+
+// RPCServeFunc 在nats的回调中执行，阻塞时后面的rpc请求排队，处理完成后调用reply回复调用方
+// reply的error转换为错误码回复
+type RPCServeFunc func(req *RPCRequest, reply func(data []byte, err error))
+
+// RPCRequest Metadata带上调用方的traceId、uid等，Src为调用方节点
+type RPCRequest struct {
+	Route    string   `json:"route"`
+	Payload  []byte   `json:"payload"`
+	Metadata Metadata `json:"metadata"`
+}
+
+type rpcResponse struct {
+	Code int    `json:"code"` // 0成功
+	Msg  string `json:"msg"`
+	Data []byte `json:"data"`
+}
+
+// rpc请求使用单独的subject，与connector转发的客户端消息分开
+func rpcSubject(serverId string) string {
+	return serverId + ".rpc"
+}
+
+// Request 调用dst节点的rpc路由，等待回复或者超时，timeout为0时使用默认超时
+func (n *NatsClient) Request(dst, route string, payload []byte, timeout time.Duration) ([]byte, error) {
+	return n.RequestContext(context.Background(), dst, route, payload, timeout)
+}
+
+// RequestContext ctx中的请求元数据传给对方，ctx的deadline早于timeout时以deadline为准
+func (n *NatsClient) RequestContext(ctx context.Context, dst, route string, payload []byte, timeout time.Duration) ([]byte, error) {
+	if n.conn == nil {
+		return nil, errs.RPCUnavailable
+	}
+	if timeout <= 0 {
+		timeout = defaultRPCTimeout
+	}
+	md, _ := FromContext(ctx)
+	md.Src = n.serverId
+	data, _ := json.Marshal(RPCRequest{
+		Route:    route,
+		Payload:  payload,
+		Metadata: md,
+	})
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	msg, err := n.conn.RequestWithContext(ctx, rpcSubject(dst), data)
+	if err != nil {
+		if errors.Is(err, nats.ErrTimeout) || errors.Is(err, context.DeadlineExceeded) {
+			return nil, errs.RPCTimeout
+		}
+		if errors.Is(err, nats.ErrNoResponders) {
+			return nil, errs.RPCUnavailable
+		}
+		return nil, err
+	}
+	var res rpcResponse
+	if err := json.Unmarshal(msg.Data, &res); err != nil {
+		return nil, err
+	}
+	switch res.Code {
+	case 0:
+		return res.Data, nil
+	case errs.RPCRouteNotFound.Code:
+		return nil, errs.RPCRouteNotFound
+	default:
+		return nil, errs.NewError(res.Code, errors.New(res.Msg))
+	}
+}
+
+// ServeRPC 订阅本节点的rpc请求，handler负责控制并发
+func (n *NatsClient) ServeRPC(handler RPCServeFunc) error {
+	_, err := n.conn.Subscribe(rpcSubject(n.serverId), func(msg *nats.Msg) {
+		var req RPCRequest
+		if err := json.Unmarshal(msg.Data, &req); err != nil {
+			respondRPC(msg, nil, err)
+			return
+		}
+		handler(&req, func(data []byte, err error) {
+			respondRPC(msg, data, err)
+		})
+	})
+	return err
+}
+
+func respondRPC(msg *nats.Msg, data []byte, err error) {
+	res := rpcResponse{
+		Data: data,
+	}
+	if err != nil {
+		body := errs.Wrap(err).Body()
+		res.Code, res.Msg, res.Data = body.Code, body.Msg, nil
+	}
+	buf, _ := json.Marshal(res)
+	if err := msg.Respond(buf); err != nil {
+		zap.L().Error("nats respond rpc err: ", zap.Error(err))
+	}
+}
@@ -1,9 +1,11 @@
 package remote
 
 import (
+	"context"
 	"encoding/json"
 	"framework/protocol"
 	"sync"
+	"time"
 
 	"go.uber.org/zap"
 )
@@ -15,8 +17,20 @@ type Session struct {
 	pushChan        chan *userPushMsg
 	data            map[string]any
 	pushSessionChan chan map[string]any
+	presence        Presence
+	channels        ChannelStore
+	localChannels   ChannelStore
+	ctx             context.Context
+	located         map[string]string // 查询过的用户所在connector，请求的用户一定在转发请求的connector
+	locatedAt       time.Time
 }
 
+const (
+	locateTimeout = 3 * time.Second
+	// 同一个请求内多次push和kick复用查询结果，定时任务中保存的session超过后重新查询
+	locateCacheTTL = time.Second
+)
+
 type pushMsg struct {
 	data   []byte
 	router string
@@ -34,16 +48,89 @@ func NewSession(client Client, msg *Msg) *Session {
 		pushChan:        make(chan *userPushMsg, 1024),
 		data:            make(map[string]any),
 		pushSessionChan: make(chan map[string]any, 1024),
+		ctx:             context.Background(),
+		located:         make(map[string]string),
+		locatedAt:       time.Now(),
+	}
+	// 下线通知的用户已经不在转发的connector上
+	if msg != nil && msg.Uid != "" && msg.Type != OfflineType {
+		s.located[msg.Uid] = msg.Src
 	}
 	go s.pushChanRead()
 	go s.pushSessionChanRead()
 	return s
 }
 
+// SetPresence 设置后push按照用户所在的connector分别发送，否则都发给转发当前请求的connector
+func (s *Session) SetPresence(presence Presence) {
+	s.Lock()
+	defer s.Unlock()
+	s.presence = presence
+}
+
+// SetChannelStore 设置频道成员的存储，没有设置时频道操作返回 ErrNoChannelStore
+func (s *Session) SetChannelStore(store ChannelStore) {
+	s.Lock()
+	defer s.Unlock()
+	s.channels = store
+}
+
+// SetContext 设置本次请求的上下文，node节点在处理超时后取消
+func (s *Session) SetContext(ctx context.Context) {
+	s.Lock()
+	defer s.Unlock()
+	s.ctx = ctx
+}
+
+// Context 耗时的handler需要检查ctx.Done()，超时后尽快返回
+func (s *Session) Context() context.Context {
+	s.RLock()
+	defer s.RUnlock()
+	return s.ctx
+}
+
+// SetLocalChannelStore 设置 LocalChannel 的存储
+func (s *Session) SetLocalChannelStore(store ChannelStore) {
+	s.Lock()
+	defer s.Unlock()
+	s.localChannels = store
+}
+
+func (s *Session) channelStore(local bool) ChannelStore {
+	s.RLock()
+	defer s.RUnlock()
+	if local {
+		return s.localChannels
+	}
+	return s.channels
+}
+
+// Channel 返回指定名字的频道，频道在第一次添加成员时创建，存储由 SetChannelStore 决定，可以多个节点共享
+func (s *Session) Channel(name string) *Channel {
+	return &Channel{
+		name:    name,
+		session: s,
+	}
+}
+
+// LocalChannel 只在本节点可见的频道，例如房间，成员只在一个节点上维护，不需要访问redis
+func (s *Session) LocalChannel(name string) *Channel {
+	return &Channel{
+		name:    name,
+		session: s,
+		local:   true,
+	}
+}
+
 func (s *Session) GetUid() string {
 	return s.msg.Uid
 }
 
+// GetRouter 当前请求的路由，例如 gameHandler.roomMessageNotify
+func (s *Session) GetRouter() string {
+	return s.msg.Router
+}
+
 func (s *Session) Push(users []string, data any, router string) {
 	msg, _ := json.Marshal(data)
 	pm := pushMsg{
@@ -59,25 +146,94 @@ func (s *Session) Push(users []string, data any, router string) {
 
 func (s *Session) pushChanRead() {
 	for data := range s.pushChan {
+		// push 没有消息id，下线等事件的msg也没有Body
 		pushMessage := protocol.Message{
 			Type:  protocol.Push,
-			ID:    s.msg.Body.ID,
 			Route: data.PushMsg.router,
 			Data:  data.PushMsg.data,
 		}
+		// 每个connector只发一条消息
+		for dst, users := range s.groupByConnector(data.Users) {
+			msg := Msg{
+				Dst:      dst,
+				Src:      s.msg.Dst,
+				Body:     &pushMessage,
+				Cid:      s.msg.Cid,
+				Uid:      s.msg.Uid,
+				PushUser: users,
+			}
+			result, _ := json.Marshal(msg)
+			zap.L().Info("push message dst: " + msg.Dst)
+			err := s.client.SendMsg(msg.Dst, result)
+			if err != nil {
+				zap.L().Sugar().Errorf("push message err:%v, msg=%v", err, msg)
+			}
+		}
+	}
+}
+
+// 按照在线记录把用户分到所在的connector，查不到的发给转发当前请求的connector
+// 已经查询过的用户不再访问presence，只推送给请求的用户时不需要查询
+func (s *Session) groupByConnector(users []string) map[string][]string {
+	groups := make(map[string][]string)
+	s.Lock()
+	presence := s.presence
+	if presence == nil {
+		s.Unlock()
+		groups[s.msg.Src] = users
+		return groups
+	}
+	if time.Since(s.locatedAt) > locateCacheTTL {
+		s.located = make(map[string]string)
+		s.locatedAt = time.Now()
+	}
+	var unknown []string
+	for _, uid := range users {
+		if dst, ok := s.located[uid]; ok {
+			groups[dst] = append(groups[dst], uid)
+		} else {
+			unknown = append(unknown, uid)
+		}
+	}
+	s.Unlock()
+	if len(unknown) == 0 {
+		return groups
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), locateTimeout)
+	defer cancel()
+	located, err := presence.Locate(ctx, unknown)
+	if err != nil {
+		zap.L().Sugar().Errorf("locate push users err:%v", err)
+	}
+	s.Lock()
+	defer s.Unlock()
+	for _, uid := range unknown {
+		dst, ok := located[uid]
+		if !ok {
+			dst = s.msg.Src
+		}
+		// 查询失败时不缓存，下次push重新查询
+		if err == nil {
+			s.located[uid] = dst
+		}
+		groups[dst] = append(groups[dst], uid)
+	}
+	return groups
+}
+
+// Kick 请求用户所在的connector踢出用户，例如账号封禁，和push一样按在线记录查找connector
+func (s *Session) Kick(uid string, reason protocol.KickReason) {
+	for dst := range s.groupByConnector([]string{uid}) {
 		msg := Msg{
-			Dst:      s.msg.Src,
-			Src:      s.msg.Dst,
-			Body:     &pushMessage,
-			Cid:      s.msg.Cid,
-			Uid:      s.msg.Uid,
-			PushUser: data.Users,
+			Dst:  dst,
+			Src:  s.msg.Dst,
+			Uid:  uid,
+			Type: KickType,
+			Kick: &reason,
 		}
-		result, _ := json.Marshal(msg)
-		zap.L().Info("push message dst: " + msg.Dst)
-		err := s.client.SendMsg(msg.Dst, result)
-		if err != nil {
-			zap.L().Sugar().Errorf("push message err:%v, msg=%v", err, msg)
+		res, _ := json.Marshal(msg)
+		if err := s.client.SendMsg(msg.Dst, res); err != nil {
+			zap.L().Error("kick user err:", zap.Error(err))
 		}
 	}
 }
@@ -0,0 +1,21 @@
+package serializer
+
+import "encoding/json"
+
+type JsonSerializer struct{}
+
+func NewJsonSerializer() *JsonSerializer {
+	return &JsonSerializer{}
+}
+
+func (j *JsonSerializer) Name() string {
+	return JSON
+}
+
+func (j *JsonSerializer) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (j *JsonSerializer) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
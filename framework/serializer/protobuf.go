@@ -0,0 +1,225 @@
+package serializer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ProtobufSerializer 和pomelo-protobuf一样按路由注册消息类型，注册了消息的路由按protobuf编码，其他路由仍然是json
+// 客户端使用同一份proto定义，proto的字段名需要和json的字段名一致，例如 string roomID = 1;
+// 游戏的push都使用同一个路由，按消息体中的pushRouter注册消息类型，push消息的1号字段必须是 string pushRouter
+// 消息体不符合注册的消息类型时按json发送，json以'{'开头，proto3的编码不会以该字节开头，接收方据此区分
+type ProtobufSerializer struct {
+	lock   sync.RWMutex
+	client map[string]proto.Message // 客户端发送的请求、通知
+	server map[string]proto.Message // 服务端的回复
+	push   map[string]proto.Message // 服务端的推送，key为pushRouter
+}
+
+func NewProtobufSerializer() *ProtobufSerializer {
+	return &ProtobufSerializer{
+		client: make(map[string]proto.Message),
+		server: make(map[string]proto.Message),
+		push:   make(map[string]proto.Message),
+	}
+}
+
+func (p *ProtobufSerializer) Name() string {
+	return Protobuf
+}
+
+// RegisterClientMessage 客户端发送到route的消息体类型
+func (p *ProtobufSerializer) RegisterClientMessage(route string, msg proto.Message) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.client[route] = msg
+}
+
+// RegisterServerMessage 服务端回复route的请求的消息体类型
+func (p *ProtobufSerializer) RegisterServerMessage(route string, msg proto.Message) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.server[route] = msg
+}
+
+// RegisterPushMessage 服务端推送的消息体类型，msg的1号字段不是 string pushRouter 时panic
+func (p *ProtobufSerializer) RegisterPushMessage(pushRouter string, msg proto.Message) {
+	fd := msg.ProtoReflect().Descriptor().Fields().ByNumber(pushRouterField)
+	if fd == nil || fd.JSONName() != "pushRouter" || fd.Kind() != protoreflect.StringKind || fd.IsList() {
+		panic(fmt.Sprintf("serializer: push message %s field 1 must be string pushRouter", msg.ProtoReflect().Descriptor().FullName()))
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.push[pushRouter] = msg
+}
+
+// Empty 没有注册任何消息，握手时降级为json
+func (p *ProtobufSerializer) Empty() bool {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return len(p.client) == 0 && len(p.server) == 0 && len(p.push) == 0
+}
+
+func (p *ProtobufSerializer) message(dir Direction, route string) proto.Message {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	if dir == ClientMessage {
+		return p.client[route]
+	}
+	return p.server[route]
+}
+
+func (p *ProtobufSerializer) pushMessage(pushRouter string) proto.Message {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return p.push[pushRouter]
+}
+
+func (p *ProtobufSerializer) hasPush() bool {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return len(p.push) > 0
+}
+
+func (p *ProtobufSerializer) Marshal(v any) ([]byte, error) {
+	pm, ok := v.(proto.Message)
+	if !ok {
+		return nil, ErrWrongValueType
+	}
+	return proto.Marshal(pm)
+}
+
+func (p *ProtobufSerializer) Unmarshal(data []byte, v any) error {
+	pm, ok := v.(proto.Message)
+	if !ok {
+		return ErrWrongValueType
+	}
+	return proto.Unmarshal(data, pm)
+}
+
+// EncodeRoute json按路由或者pushRouter注册的消息转换为protobuf，没有注册或者不符合消息类型时原样返回json
+func (p *ProtobufSerializer) EncodeRoute(dir Direction, route string, data []byte) ([]byte, error) {
+	tmpl := p.message(dir, route)
+	if tmpl == nil && dir == ServerMessage && p.hasPush() {
+		var head struct {
+			PushRouter string `json:"pushRouter"`
+		}
+		if json.Unmarshal(data, &head) == nil && head.PushRouter != "" {
+			tmpl = p.pushMessage(head.PushRouter)
+		}
+	}
+	if tmpl == nil {
+		return data, nil
+	}
+	msg := tmpl.ProtoReflect().New().Interface()
+	if err := protojson.Unmarshal(data, msg); err != nil {
+		// 例如 [][]int 等proto表示不了的字段，按json发送，不丢弃数据
+		zap.L().Sugar().Debugf("route %s body does not match %s, send json: %v", route, msg.ProtoReflect().Descriptor().FullName(), err)
+		return data, nil
+	}
+	return proto.Marshal(msg)
+}
+
+// DecodeRoute protobuf按路由或者pushRouter注册的消息转换为json，json原样返回
+func (p *ProtobufSerializer) DecodeRoute(dir Direction, route string, data []byte) ([]byte, error) {
+	if len(data) == 0 || data[0] == '{' {
+		return data, nil
+	}
+	tmpl := p.message(dir, route)
+	if tmpl == nil && dir == ServerMessage {
+		tmpl = p.pushMessage(peekPushRouter(data))
+	}
+	if tmpl == nil {
+		return nil, fmt.Errorf("serializer: no protobuf message registered for route %s", route)
+	}
+	msg := tmpl.ProtoReflect().New().Interface()
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return nil, err
+	}
+	// protojson会把64位整数输出为字符串，handler按数字解析，所以自己转换
+	return json.Marshal(messageToJSON(msg.ProtoReflect()))
+}
+
+const pushRouterField = 1
+
+// peekPushRouter 读取push消息的1号字段，不解析整个消息
+func peekPushRouter(data []byte) string {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return ""
+		}
+		data = data[n:]
+		if num == pushRouterField && typ == protowire.BytesType {
+			v, n := protowire.ConsumeBytes(data)
+			if n < 0 {
+				return ""
+			}
+			return string(v)
+		}
+		n = protowire.ConsumeFieldValue(num, typ, data)
+		if n < 0 {
+			return ""
+		}
+		data = data[n:]
+	}
+	return ""
+}
+
+// messageToJSON 和protojson的 EmitUnpopulated 一样输出零值字段，字段名使用json名，和json序列化的结果一致
+func messageToJSON(m protoreflect.Message) map[string]any {
+	fields := m.Descriptor().Fields()
+	res := make(map[string]any, fields.Len())
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		// oneof的其他成员不输出
+		if fd.ContainingOneof() != nil && !m.Has(fd) {
+			continue
+		}
+		v := m.Get(fd)
+		switch {
+		case fd.IsList():
+			list := v.List()
+			values := make([]any, list.Len())
+			for i := range values {
+				values[i] = valueToJSON(fd, list.Get(i))
+			}
+			res[fd.JSONName()] = values
+		case fd.IsMap():
+			values := make(map[string]any)
+			v.Map().Range(func(k protoreflect.MapKey, mv protoreflect.Value) bool {
+				values[k.String()] = valueToJSON(fd.MapValue(), mv)
+				return true
+			})
+			res[fd.JSONName()] = values
+		case fd.Message() != nil && !m.Has(fd):
+			res[fd.JSONName()] = nil
+		default:
+			res[fd.JSONName()] = valueToJSON(fd, v)
+		}
+	}
+	return res
+}
+
+func valueToJSON(fd protoreflect.FieldDescriptor, v protoreflect.Value) any {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return messageToJSON(v.Message())
+	case protoreflect.EnumKind:
+		return int32(v.Enum())
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return json.Number(strconv.FormatInt(v.Int(), 10))
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return json.Number(strconv.FormatUint(v.Uint(), 10))
+	default:
+		return v.Interface()
+	}
+}
@@ -0,0 +1,86 @@
+package serializer
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	JSON     = "json"
+	Protobuf = "protobuf"
+)
+
+var (
+	ErrWrongValueType = errors.New("serializer: value type not supported")
+)
+
+// Serializer 负责消息体的编解码，握手时由客户端通过 Sys.Serializer 协商
+type Serializer interface {
+	Name() string
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// Direction 消息的方向，按路由转换消息体时区分请求和回复
+type Direction int
+
+const (
+	ClientMessage Direction = iota // 客户端发送的请求、通知
+	ServerMessage                  // 服务端的回复、推送
+)
+
+// RouteCodec 按路由转换消息体，connector和node内部统一使用json，只在和客户端收发时转换
+type RouteCodec interface {
+	EncodeRoute(dir Direction, route string, data []byte) ([]byte, error) // json转换为协商的格式
+	DecodeRoute(dir Direction, route string, data []byte) ([]byte, error) // 协商的格式转换为json
+}
+
+var (
+	lock        sync.RWMutex
+	serializers = make(map[string]Serializer)
+	protobuf    = NewProtobufSerializer()
+)
+
+func init() {
+	Register(NewJsonSerializer())
+	Register(protobuf)
+}
+
+// RegisterClientMessage 注册客户端发送到route的protobuf消息类型
+func RegisterClientMessage(route string, msg proto.Message) {
+	protobuf.RegisterClientMessage(route, msg)
+}
+
+// RegisterServerMessage 注册服务端回复route的请求的protobuf消息类型
+func RegisterServerMessage(route string, msg proto.Message) {
+	protobuf.RegisterServerMessage(route, msg)
+}
+
+// RegisterPushMessage 注册消息体中pushRouter对应的protobuf消息类型
+func RegisterPushMessage(pushRouter string, msg proto.Message) {
+	protobuf.RegisterPushMessage(pushRouter, msg)
+}
+
+// Register 注册序列化器，同名覆盖
+func Register(s Serializer) {
+	lock.Lock()
+	defer lock.Unlock()
+	serializers[strings.ToLower(s.Name())] = s
+}
+
+// Get 根据名称获取序列化器
+func Get(name string) (Serializer, bool) {
+	lock.RLock()
+	defer lock.RUnlock()
+	s, ok := serializers[strings.ToLower(strings.TrimSpace(name))]
+	return s, ok
+}
+
+// Default 默认使用json
+func Default() Serializer {
+	s, _ := Get(JSON)
+	return s
+}
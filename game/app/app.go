@@ -1,9 +1,11 @@
 package app
 
 import (
+	"common/biz"
 	"common/config"
 	"common/logs"
 	"context"
+	"core/dao"
 	"core/repo"
 	"framework/node"
 	"game/route"
@@ -15,6 +17,8 @@ import (
 	"go.uber.org/zap"
 )
 
+const slowHandler = 500 * time.Millisecond
+
 func Run(ctx context.Context, serverId string) error {
 	// 初始化日志服务
 	logs.InitLogger(&config.Conf.Log)
@@ -24,7 +28,22 @@ func Run(ctx context.Context, serverId string) error {
 		n := node.Default()
 		exit = n.Close
 		manager := repo.New()
-		n.RegisterHandler(route.Register(manager))
+		handlers, offline, resume, err := route.Register(manager)
+		if err != nil {
+			zap.L().Fatal("register handlers err: ", zap.Error(err))
+		}
+		n.RegisterHandler(handlers)
+		n.RegisterOfflineHandler(offline)
+		n.RegisterResumeHandler(resume)
+		// 所有路由都需要登录
+		n.Use(node.Logging(), node.Recover(), node.Timing(slowHandler), node.Auth(biz.InvalidUsers))
+		for path, validate := range route.Validators() {
+			n.UseRoute(path, validate)
+		}
+		// push发给用户所在的connector
+		n.SetPresence(dao.NewPresenceDao(manager))
+		// 联盟频道的成员保存在redis中，所有game节点共享，房间频道只在本节点内存中
+		n.SetChannelStore(dao.NewChannelDao(manager))
 		n.Run(serverId)
 	}()
 	stop := func() {
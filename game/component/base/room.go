@@ -1,7 +1,12 @@
 package base
 
-import "game/component/proto"
+import (
+	"framework/remote"
+	"game/component/proto"
+)
 
 type RoomFrame interface {
 	GetUsers() map[string]*proto.RoomUser
+	// Channel 房间内所有用户的频道
+	Channel(session *remote.Session) *remote.Channel
 }
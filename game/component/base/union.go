@@ -1,5 +1,11 @@
 package base
 
+import "framework/remote"
+
 type UnionBase interface {
 	DismissRoom(roomId string)
+	// UserEntry 用户进入联盟的房间，加入联盟频道
+	UserEntry(session *remote.Session, uid string)
+	// UserLeave 用户离开联盟的房间，退出联盟频道
+	UserLeave(session *remote.Session, uid string)
 }
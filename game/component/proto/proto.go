@@ -107,3 +107,25 @@ func OtherUserEntryRoomPushData(roomUserInfo *RoomUser) any {
 	}
 	return pushMsg
 }
+
+func UserReconnectPushData(chairID int) any {
+	pushMsg := map[string]any{
+		"pushRouter": "RoomMessagePush",
+		"type":       UserReconnectPush,
+		"data": map[string]any{
+			"chairID": chairID,
+		},
+	}
+	return pushMsg
+}
+
+func UserOffLinePushData(chairID int) any {
+	pushMsg := map[string]any{
+		"pushRouter": "RoomMessagePush",
+		"type":       UserOffLinePush,
+		"data": map[string]any{
+			"chairID": chairID,
+		},
+	}
+	return pushMsg
+}
@@ -14,9 +14,12 @@ import (
 	"go.uber.org/zap"
 )
 
+// Room 房间的导出方法加锁，内部方法和 GameFrame 的回调在锁内执行
+// node节点不同房间的消息并发处理，踢人的定时任务也在单独的协程中执行
 type Room struct {
 	sync.RWMutex
 	Id            string
+	channelName   string
 	UnionId       int64
 	Union         base.UnionBase
 	gameRule      proto.GameRule
@@ -31,6 +34,7 @@ type Room struct {
 func NewRoom(roomId string, unionId int64, gameRule proto.GameRule, u base.UnionBase) *Room {
 	r := &Room{
 		Id:            roomId,
+		channelName:   "room:" + roomId,
 		UnionId:       unionId,
 		gameRule:      gameRule,
 		users:         make(map[string]*proto.RoomUser),
@@ -44,6 +48,8 @@ func NewRoom(roomId string, unionId int64, gameRule proto.GameRule, u base.Union
 }
 
 func (r *Room) UserEntryRoom(session *remote.Session, data *entity.User) *err.Error {
+	r.Lock()
+	defer r.Unlock()
 	r.RoomCreator = &proto.RoomCreator{
 		Uid: data.Uid,
 	}
@@ -58,6 +64,10 @@ func (r *Room) UserEntryRoom(session *remote.Session, data *entity.User) *err.Er
 	if !ok {
 		r.users[data.Uid] = proto.ToRoomUser(data, chairID)
 	}
+	if err := r.Channel(session).Add(data.Uid); err != nil {
+		zap.L().Error("add user to room channel err: ", zap.Error(err))
+	}
+	r.Union.UserEntry(session, data.Uid)
 	// 2.将房间号推送给客户端 更新数据库 当前房间号存储起来
 	r.UpdateUserInfoPush(session, data.Uid)
 	session.Put("roomId", r.Id)
@@ -66,7 +76,7 @@ func (r *Room) UserEntryRoom(session *remote.Session, data *entity.User) *err.Er
 	// 4.告诉其他人此用户进入房间了
 	r.OtherUserEntryRoomPush(session, data.Uid)
 	// 定时踢出未准备的玩家
-	go r.addKickScheduleEvent(session, data.Uid)
+	r.addKickScheduleEvent(session, data.Uid)
 	return nil
 }
 
@@ -88,6 +98,11 @@ func (r *Room) ServerMessagePush(session *remote.Session, data any, users []stri
 	session.Push(users, data, "ServerMessagePush")
 }
 
+// Channel 房间内所有用户的频道，房间只在创建它的节点上，频道保存在本节点内存中，推送不访问redis
+func (r *Room) Channel(session *remote.Session) *remote.Channel {
+	return session.LocalChannel(r.channelName)
+}
+
 func (r *Room) SelfEntryRoomPush(session *remote.Session, uid string) {
 	// {
 	// 	gameType: 1,
@@ -103,6 +118,8 @@ func (r *Room) SelfEntryRoomPush(session *remote.Session, uid string) {
 }
 
 func (r *Room) RoomMessageHandle(session *remote.Session, req request.RoomMessageReq) {
+	r.Lock()
+	defer r.Unlock()
 	if req.Type == proto.UserReadyNotify {
 		r.userReady(session.GetUid(), session)
 	}
@@ -132,14 +149,14 @@ func (r *Room) getRoomSceneInfoPush(session *remote.Session) {
 
 // 添加定时踢出未准备的用户 定时任务
 func (r *Room) addKickScheduleEvent(session *remote.Session, uid string) {
-	r.Lock()
-	defer r.Unlock()
 	task, ok := r.kickSchedules[uid]
 	if ok {
 		task.Stop()
 		delete(r.kickSchedules, uid)
 	}
 	r.kickSchedules[uid] = time.AfterFunc(30*time.Second, func() {
+		r.Lock()
+		defer r.Unlock()
 		zap.L().Info("kick 定时执行，代表 用户长时间未准备,uid=" + uid)
 		// 取消定时任务
 		timer, ok := r.kickSchedules[uid]
@@ -155,7 +172,7 @@ func (r *Room) addKickScheduleEvent(session *remote.Session, uid string) {
 				r.kickUser(user, session)
 				// 判断是否需要解散房间（如果房间里一个人都没有的话就解散房间）
 				if len(r.users) == 0 {
-					r.dismissRoom()
+					r.dismissRoom(session)
 				}
 			}
 		}
@@ -167,25 +184,27 @@ func (r *Room) kickUser(user *proto.RoomUser, session *remote.Session) {
 	// 将房间roomID置为空
 	r.ServerMessagePush(session, proto.UpdateUserInfoPush(""), []string{user.UserInfo.Uid})
 	// 通知房间内其他的所有人该用户离开房间
-	users := make([]string, 0, len(r.users))
-	for _, v := range r.users {
-		users = append(users, v.UserInfo.Uid)
-	}
-	r.ServerMessagePush(session, proto.UserLeaveRoomPushData(user), users)
+	channel := r.Channel(session)
+	channel.Push(proto.UserLeaveRoomPushData(user), "ServerMessagePush")
 	// 删除该用户
 	delete(r.users, user.UserInfo.Uid)
+	if err := channel.Remove(user.UserInfo.Uid); err != nil {
+		zap.L().Error("remove user from room channel err: ", zap.Error(err))
+	}
+	r.Union.UserLeave(session, user.UserInfo.Uid)
 }
 
 // 解散房间
-func (r *Room) dismissRoom() {
-	r.Lock()
-	defer r.Unlock()
+func (r *Room) dismissRoom(session *remote.Session) {
 	if r.isDismissed {
 		return
 	}
 	r.isDismissed = true
 	// 取消所有的定时任务
 	r.cancelAllScheduler()
+	if err := r.Channel(session).Destroy(); err != nil {
+		zap.L().Error("destroy room channel err: ", zap.Error(err))
+	}
 	r.Union.DismissRoom(r.Id)
 }
 
@@ -209,7 +228,7 @@ func (r *Room) userReady(uid string, session *remote.Session) {
 		task.Stop()
 		delete(r.kickSchedules, uid)
 	}
-	r.ServerMessagePush(session, proto.UserReadyPushData(user.ChairID), r.getAllUsers())
+	r.Channel(session).Push(proto.UserReadyPushData(user.ChairID), "ServerMessagePush")
 
 	// 2.判断是否开始游戏
 	if r.IsStartGame() {
@@ -223,32 +242,40 @@ func (r *Room) JoinRoom(session *remote.Session, data *entity.User) *err.Error {
 
 // OtherUserEntryRoomPush 通知其他用户进入房间了
 func (r *Room) OtherUserEntryRoomPush(session *remote.Session, uid string) {
-	others := make([]string, 0, len(r.users))
-	for _, v := range r.users {
-		if v.UserInfo.Uid != uid {
-			others = append(others, v.UserInfo.Uid)
-		}
-	}
 	user, ok := r.users[uid]
 	if ok {
-		r.ServerMessagePush(session, proto.OtherUserEntryRoomPushData(user), others)
+		r.Channel(session).PushExcept(proto.OtherUserEntryRoomPushData(user), "ServerMessagePush", uid)
 	}
 }
 
-func (r *Room) getAllUsers() []string {
-	users := make([]string, 0, len(r.users))
-	for _, v := range r.users {
-		users = append(users, v.UserInfo.Uid)
+// UserOffline 用户掉线，标记状态并通知房间内其他用户
+func (r *Room) UserOffline(session *remote.Session) {
+	r.Lock()
+	defer r.Unlock()
+	user, ok := r.users[session.GetUid()]
+	if !ok {
+		return
+	}
+	user.UserStatus |= proto.Offline
+	r.Channel(session).PushExcept(proto.UserOffLinePushData(user.ChairID), "ServerMessagePush", user.UserInfo.Uid)
+}
+
+// UserResume 用户断线后重连回来，清除掉线状态并通知房间内其他用户
+func (r *Room) UserResume(session *remote.Session) {
+	r.Lock()
+	defer r.Unlock()
+	user, ok := r.users[session.GetUid()]
+	if !ok {
+		return
 	}
-	return users
+	user.UserStatus &^= proto.Offline
+	r.Channel(session).PushExcept(proto.UserReconnectPushData(user.ChairID), "ServerMessagePush", user.UserInfo.Uid)
 }
 
 func (r *Room) getEmptyChairID() int {
 	if len(r.users) == 0 {
 		return 0
 	}
-	r.Lock()
-	defer r.Unlock()
 	chairId := 0
 	for _, v := range r.users {
 		if chairId == v.ChairID {
@@ -258,7 +285,7 @@ func (r *Room) getEmptyChairID() int {
 	return chairId
 }
 
-// IsStartGame 判断是否开始游戏
+// IsStartGame 判断是否开始游戏，调用时需要持有房间的锁
 func (r *Room) IsStartGame() bool {
 	readyUserCount := 0
 	for _, v := range r.users {
@@ -285,6 +312,7 @@ func (r *Room) startGame(session *remote.Session, user *proto.RoomUser) {
 	r.GameFrame.StartGame(session, user)
 }
 
+// GetUsers 给 GameFrame 使用，调用时已经持有房间的锁
 func (r *Room) GetUsers() map[string]*proto.RoomUser {
 	return r.users
 }
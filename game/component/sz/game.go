@@ -45,29 +45,28 @@ func (g *GameFrame) GetGameData(session *remote.Session) any {
 }
 
 func (g *GameFrame) StartGame(session *remote.Session, user *proto.RoomUser) {
-	users := g.getAllUsers()
 	// 1.用户信息变更推送（金币变化） {"gold": 9958, "pushRouter": 'UpdateUserInfoPush'}
-	g.ServerMessagePush(session, UpdateUserInfoPushData(user.UserInfo.Gold), users)
+	g.pushAll(session, UpdateUserInfoPushData(user.UserInfo.Gold))
 	// 2.庄家推送 {"type":414,"data":{"bankerChairID":0},"pushRouter":"GameMessagePush"}
 	if g.gameData.CurBureau == 0 { // 第一轮庄家随机，后面的轮次 霸王庄（赢的人是庄家）
-		g.gameData.BankerChairID = rand.IntN(len(users))
+		g.gameData.BankerChairID = rand.IntN(len(g.r.GetUsers()))
 	}
 	g.gameData.CurChairID = g.gameData.BankerChairID
-	g.ServerMessagePush(session, GameBankerPushData(g.gameData.BankerChairID), users)
+	g.pushAll(session, GameBankerPushData(g.gameData.BankerChairID))
 	// 3.局数推送{"type":411,"data":{"curBureau":6},"pushRouter":"GameMessagePush"}
 	g.gameData.CurBureau++
-	g.ServerMessagePush(session, GameBureauPushData(g.gameData.CurBureau), users)
+	g.pushAll(session, GameBureauPushData(g.gameData.CurBureau))
 	// 4.游戏状态推送 分两步推送
 	// 第一步 推送 发牌 牌发完之后, 第二步 推送下分 需要用户操作了 推送操作
 	// {"type":401,"data":{"gameStatus":1,"tick":0},"pushRouter":"GameMessagePush"}
 	g.gameData.GameStatus = SendCards
-	g.ServerMessagePush(session, GameStatusPushData(g.gameData.GameStatus, 0), users)
+	g.pushAll(session, GameStatusPushData(g.gameData.GameStatus, 0))
 	// 5.发牌推送
 	g.sendCards(session)
 	// 6.下分推送
 	// 先推送下分状态
 	g.gameData.GameStatus = PourScore
-	g.ServerMessagePush(session, GameStatusPushData(g.gameData.GameStatus, 30), users)
+	g.pushAll(session, GameStatusPushData(g.gameData.GameStatus, 30))
 	g.gameData.CurScore = g.gameRule.BaseScore * g.gameRule.AddScores[0]
 	for _, v := range g.r.GetUsers() {
 		g.ServerMessagePush(session, GamePourScorePushData(v.ChairID, g.gameData.CurScore, g.gameData.CurScore,
@@ -75,7 +74,7 @@ func (g *GameFrame) StartGame(session *remote.Session, user *proto.RoomUser) {
 	}
 	// 7. 轮数推送
 	g.gameData.Round = 1
-	g.ServerMessagePush(session, GameRoundPushData(g.gameData.Round), users)
+	g.pushAll(session, GameRoundPushData(g.gameData.Round))
 	// 8. 操作推送
 	for _, v := range g.r.GetUsers() {
 		// GameTurnPushData ChairID是做操作的座次号（是哪个用户在做操作）
@@ -87,12 +86,9 @@ func (g *GameFrame) ServerMessagePush(session *remote.Session, data any, users [
 	session.Push(users, data, "ServerMessagePush")
 }
 
-func (g *GameFrame) getAllUsers() []string {
-	users := make([]string, 0, len(g.r.GetUsers()))
-	for _, v := range g.r.GetUsers() {
-		users = append(users, v.UserInfo.Uid)
-	}
-	return users
+// 推送给房间内的所有用户
+func (g *GameFrame) pushAll(session *remote.Session, data any) {
+	g.r.Channel(session).Push(data, "ServerMessagePush")
 }
 
 func (g *GameFrame) sendCards(session *remote.Session) {
@@ -109,7 +105,7 @@ func (g *GameFrame) sendCards(session *remote.Session) {
 			hands[i] = []int{0, 0, 0}
 		}
 	}
-	g.ServerMessagePush(session, GameSendCardsPushData(hands), g.getAllUsers())
+	g.pushAll(session, GameSendCardsPushData(hands))
 }
 
 func (g *GameFrame) IsPlayingChairID(chairID int) bool {
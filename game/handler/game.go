@@ -1,13 +1,13 @@
 package handler
 
 import (
-	"common"
 	"common/biz"
 	"core/repo"
 	"core/service"
-	"encoding/json"
 	"fmt"
+	"framework/node"
 	"framework/remote"
+	"game/component/room"
 	"game/logic"
 	"game/models/request"
 )
@@ -25,22 +25,43 @@ func NewGameHandler(r *repo.Manager, um *logic.UnionManager) *GameHandler {
 }
 
 func (g *GameHandler) RoomMessageNotify(session *remote.Session, msg []byte) any {
-	if len(session.GetUid()) <= 0 {
-		common.F(biz.InvalidUsers)
+	req, ok := node.Request[request.RoomMessageReq](session)
+	if !ok {
+		return biz.RequestDataError
 	}
 
-	var req request.RoomMessageReq
-	err := json.Unmarshal(msg, &req)
+	room, err := g.sessionRoom(session)
 	if err != nil {
-		common.F(biz.RequestDataError)
+		return err
 	}
+	room.RoomMessageHandle(session, *req)
+
+	return nil
+}
 
+// sessionRoom 按session中的roomId查找用户所在的房间
+func (g *GameHandler) sessionRoom(session *remote.Session) (*room.Room, error) {
 	roomId, ok := session.Get("roomId")
 	if !ok {
-		common.F(biz.NotInRoom)
+		return nil, biz.NotInRoom
+	}
+	r := g.um.GetRoomById(fmt.Sprintf("%v", roomId))
+	if r == nil {
+		return nil, biz.RoomNotExist
 	}
-	room := g.um.GetRoomById(fmt.Sprintf("%v", roomId))
-	room.RoomMessageHandle(session, req)
+	return r, nil
+}
 
-	return nil
+// UserOffline 用户连接断开，通知房间内的其他用户
+func (g *GameHandler) UserOffline(session *remote.Session) {
+	if room, err := g.sessionRoom(session); err == nil {
+		room.UserOffline(session)
+	}
+}
+
+// UserResume 用户在宽限期内重连，通知房间内的其他用户
+func (g *GameHandler) UserResume(session *remote.Session) {
+	if room, err := g.sessionRoom(session); err == nil {
+		room.UserResume(session)
+	}
 }
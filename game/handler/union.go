@@ -6,7 +6,7 @@ import (
 	"context"
 	"core/repo"
 	"core/service"
-	"encoding/json"
+	"framework/node"
 	"framework/remote"
 	"game/logic"
 	"game/models/request"
@@ -24,65 +24,55 @@ func NewUnionHandler(r *repo.Manager, um *logic.UnionManager) *UnionHandler {
 	}
 }
 
-func (u *UnionHandler) CreateRoom(session *remote.Session, msg []byte) any {
+func (u *UnionHandler) CreateRoom(ctx context.Context, session *remote.Session, msg []byte) any {
 	// union 联盟 - 持有房间
 	// unionManager 管理联盟
 	// room 房间 - 关联 game 接口 实现多个不同的游戏
 
 	// 1.接受参数
 	uid := session.GetUid()
-	if len(uid) <= 0 {
-		return common.F(biz.InvalidUsers)
-	}
-
-	var req request.CreateRoomReq
-	err := json.Unmarshal(msg, &req)
-	if err != nil {
-		return common.F(biz.RequestDataError)
+	req, ok := node.Request[request.CreateRoomReq](session)
+	if !ok {
+		return biz.RequestDataError
 	}
 
 	// 2.根据session 用户id 查询用户信息
-	userData, err := u.userService.FindUserByUid(context.Background(), uid)
+	userData, err := u.userService.FindUserByUid(ctx, uid)
 	if err != nil {
-		return common.F(biz.SqlError)
+		return biz.SqlError
 	}
 	if userData == nil {
-		return common.F(biz.InvalidUsers)
+		return biz.InvalidUsers
 	}
 
 	// 3.根据游戏规则、游戏类型、用户信息（创建房间的用户）创建房间
 	// TODO 需要判断 session 中是否已经有roomID， 如果有代表此用户已经在房间中了， 就不能再次创建房间了
 	union := u.um.GetUnion(req.UnionID)
-	bizErr := union.CreateRoom(u.userService, session, req, userData)
+	bizErr := union.CreateRoom(u.userService, session, *req, userData)
 	if bizErr != nil {
-		return common.F(bizErr)
+		return bizErr
 	}
 
 	return common.S(nil)
 }
 
-func (u *UnionHandler) JoinRoom(session *remote.Session, msg []byte) any {
+func (u *UnionHandler) JoinRoom(ctx context.Context, session *remote.Session, msg []byte) any {
 	uid := session.GetUid()
-	if len(uid) <= 0 {
-		return common.F(biz.InvalidUsers)
-	}
-
-	var req request.JoinRoomReq
-	err := json.Unmarshal(msg, &req)
-	if err != nil {
-		return common.F(biz.RequestDataError)
+	req, ok := node.Request[request.JoinRoomReq](session)
+	if !ok {
+		return biz.RequestDataError
 	}
 	// 2.根据session 用户id 查询用户信息
-	userData, err := u.userService.FindUserByUid(context.Background(), uid)
+	userData, err := u.userService.FindUserByUid(ctx, uid)
 	if err != nil {
-		return common.F(biz.SqlError)
+		return biz.SqlError
 	}
 	if userData == nil {
-		return common.F(biz.InvalidUsers)
+		return biz.InvalidUsers
 	}
 	bizErr := u.um.JoinRoom(session, req.RoomID, userData)
 	if bizErr != nil {
-		return common.F(bizErr)
+		return bizErr
 	}
 	return common.S(nil)
 }
@@ -9,6 +9,8 @@ import (
 	"game/component/room"
 	"game/models/request"
 	"sync"
+
+	"go.uber.org/zap"
 )
 
 type Union struct {
@@ -18,8 +20,9 @@ type Union struct {
 	RoomList map[string]*room.Room
 }
 
-func NewUnion(m *UnionManager) *Union {
+func NewUnion(m *UnionManager, id int64) *Union {
 	return &Union{
+		Id:       id,
 		m:        m,
 		RoomList: make(map[string]*room.Room),
 	}
@@ -28,15 +31,43 @@ func NewUnion(m *UnionManager) *Union {
 func (u *Union) CreateRoom(service *service.UserService, session *remote.Session, req request.CreateRoomReq,
 	userData *entity.User) *err.Error {
 	// 1.创建一个房间，生成房间号
-	roomId := u.m.CreateRoomId()
+	// 生成房间号和加入RoomList需要在同一个锁内，避免两个房间使用同一个房间号
+	u.m.Lock()
+	roomId := u.m.createRoomId()
 	fmt.Println("CreateRoom roomID = ", roomId)
 	newRoom := room.NewRoom(roomId, req.UnionID, req.GameRule, u)
+	u.Lock()
 	u.RoomList[roomId] = newRoom
+	u.Unlock()
+	u.m.Unlock()
 	return newRoom.UserEntryRoom(session, userData)
 }
 
+func (u *Union) GetRoom(roomId string) *room.Room {
+	u.RLock()
+	defer u.RUnlock()
+	return u.RoomList[roomId]
+}
+
 func (u *Union) DismissRoom(roomId string) {
 	u.Lock()
 	defer u.Unlock()
 	delete(u.RoomList, roomId)
 }
+
+// Channel 联盟内所有在房间中的用户，频道存储在redis中，联盟公告在任意节点都可以按频道推送
+func (u *Union) Channel(session *remote.Session) *remote.Channel {
+	return session.Channel(fmt.Sprintf("union:%d", u.Id))
+}
+
+func (u *Union) UserEntry(session *remote.Session, uid string) {
+	if err := u.Channel(session).Add(uid); err != nil {
+		zap.L().Error("add user to union channel err: ", zap.Error(err))
+	}
+}
+
+func (u *Union) UserLeave(session *remote.Session, uid string) {
+	if err := u.Channel(session).Remove(uid); err != nil {
+		zap.L().Error("remove user from union channel err: ", zap.Error(err))
+	}
+}
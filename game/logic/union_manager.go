@@ -30,20 +30,19 @@ func (u *UnionManager) GetUnion(unionId int64) *Union {
 	if ok {
 		return union
 	}
-	union = NewUnion(u)
+	union = NewUnion(u, unionId)
 	u.UnionList[unionId] = union
 	return union
 }
 
-func (u *UnionManager) CreateRoomId() string {
-	roomId := u.genRoomId()
-	for _, v := range u.UnionList {
-		_, ok := v.RoomList[roomId]
-		if ok {
-			return u.CreateRoomId()
+// createRoomId 生成所有联盟中都不存在的房间号，调用方需要持有u.Lock，直到房间加入RoomList
+func (u *UnionManager) createRoomId() string {
+	for {
+		roomId := u.genRoomId()
+		if u.getRoomById(roomId) == nil {
+			return roomId
 		}
 	}
-	return roomId
 }
 
 func (u *UnionManager) genRoomId() string {
@@ -52,9 +51,16 @@ func (u *UnionManager) genRoomId() string {
 	return fmt.Sprintf("%d", randInt)
 }
 
+// 锁的顺序：UnionManager -> Union，Room -> Union
 func (u *UnionManager) GetRoomById(roomId string) *room.Room {
+	u.RLock()
+	defer u.RUnlock()
+	return u.getRoomById(roomId)
+}
+
+func (u *UnionManager) getRoomById(roomId string) *room.Room {
 	for _, union := range u.UnionList {
-		if r, ok := union.RoomList[roomId]; ok {
+		if r := union.GetRoom(roomId); r != nil {
 			return r
 		}
 	}
@@ -63,12 +69,9 @@ func (u *UnionManager) GetRoomById(roomId string) *room.Room {
 
 func (u *UnionManager) JoinRoom(session *remote.Session, roomId string, data *entity.User) *err.Error {
 	// 通过联盟找到具体的房间
-	for _, v := range u.UnionList {
-		room, ok := v.RoomList[roomId]
-		if ok {
-			return room.JoinRoom(session, data)
-		}
+	room := u.GetRoomById(roomId)
+	if room == nil {
+		return biz.RoomNotExist
 	}
-
-	return biz.RoomNotExist
+	return room.JoinRoom(session, data)
 }
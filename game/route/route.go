@@ -1,19 +1,30 @@
 package route
 
 import (
+	"common/biz"
 	"core/repo"
 	"framework/node"
 	"game/handler"
 	"game/logic"
+	"game/models/request"
 )
 
-func Register(r *repo.Manager) node.LogicHandler {
-	handlers := make(node.LogicHandler)
+func Register(r *repo.Manager) (node.LogicHandler, node.OfflineHandler, node.ResumeHandler, error) {
 	um := logic.NewUnionManager()
 	unionHandler := handler.NewUnionHandler(r, um)
-	handlers["unionHandler.createRoom"] = unionHandler.CreateRoom
-	handlers["unionHandler.joinRoom"] = unionHandler.JoinRoom
 	gameHandler := handler.NewGameHandler(r, um)
-	handlers["gameHandler.roomMessageNotify"] = gameHandler.RoomMessageNotify
-	return handlers
+	handlers, err := node.NewRegistrar().
+		Register(unionHandler).
+		Register(gameHandler).
+		Handlers()
+	return handlers, gameHandler.UserOffline, gameHandler.UserResume, err
+}
+
+// Validators 每个路由的请求参数，handler通过 node.Request 获取解析后的参数
+func Validators() map[string]node.Middleware {
+	return map[string]node.Middleware{
+		"unionHandler.createRoom":       node.Validate[request.CreateRoomReq](biz.RequestDataError),
+		"unionHandler.joinRoom":         node.Validate[request.JoinRoomReq](biz.RequestDataError),
+		"gameHandler.roomMessageNotify": node.Validate[request.RoomMessageReq](biz.RequestDataError),
+	}
 }
@@ -1,9 +1,11 @@
 package app
 
 import (
+	"common/biz"
 	"common/config"
 	"common/logs"
 	"context"
+	"core/dao"
 	"core/repo"
 	"framework/node"
 	"hall/route"
@@ -15,6 +17,8 @@ import (
 	"go.uber.org/zap"
 )
 
+const slowHandler = 500 * time.Millisecond
+
 func Run(ctx context.Context, serverId string) error {
 	// 初始化日志服务
 	logs.InitLogger(&config.Conf.Log)
@@ -24,7 +28,18 @@ func Run(ctx context.Context, serverId string) error {
 		n := node.Default()
 		exit = n.Close
 		manager := repo.New()
-		n.RegisterHandler(route.Register(manager))
+		handlers, err := route.Register(manager)
+		if err != nil {
+			zap.L().Fatal("register handlers err: ", zap.Error(err))
+		}
+		n.RegisterHandler(handlers)
+		// 所有路由都需要登录
+		n.Use(node.Logging(), node.Recover(), node.Timing(slowHandler), node.Auth(biz.InvalidUsers))
+		for path, validate := range route.Validators() {
+			n.UseRoute(path, validate)
+		}
+		// 和game节点共享频道，全服公告等可以在hall推送
+		n.SetChannelStore(dao.NewChannelDao(manager))
 		n.Run(serverId)
 	}()
 	stop := func() {
@@ -1,11 +1,11 @@
 package handler
 
 import (
-	"common"
 	"common/biz"
+	"context"
 	"core/repo"
 	"core/service"
-	"encoding/json"
+	"framework/node"
 	"framework/remote"
 	"hall/models/request"
 	"hall/models/response"
@@ -23,19 +23,19 @@ func NewUserHandler(r *repo.Manager) *UserHandler {
 	}
 }
 
-func (u *UserHandler) UpdateUserAddress(session *remote.Session, msg []byte) any {
+func (u *UserHandler) UpdateUserAddress(ctx context.Context, session *remote.Session, msg []byte) any {
 	zap.L().Info("UpdateUserAddress msg: " + string(msg))
-	var req request.UpdateUserAddressReq
-	if err := json.Unmarshal(msg, &req); err != nil {
-		return common.F(biz.RequestDataError)
+	req, ok := node.Request[request.UpdateUserAddressReq](session)
+	if !ok {
+		return biz.RequestDataError
 	}
 
-	err := u.userService.UpdateUserAddress(session.GetUid(), req)
+	err := u.userService.UpdateUserAddress(ctx, session.GetUid(), *req)
 	if err != nil {
-		return common.F(biz.SqlError)
+		return biz.SqlError
 	}
 	res := response.UpdateUserAddressResp{}
 	res.Code = biz.OK
-	res.UpdateUserData = req
+	res.UpdateUserData = *req
 	return res
 }
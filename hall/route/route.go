@@ -1,14 +1,23 @@
 package route
 
 import (
+	"common/biz"
 	"core/repo"
 	"framework/node"
 	"hall/handler"
+	"hall/models/request"
 )
 
-func Register(r *repo.Manager) node.LogicHandler {
-	handlers := make(node.LogicHandler)
+func Register(r *repo.Manager) (node.LogicHandler, error) {
 	userHandler := handler.NewUserHandler(r)
-	handlers["userHandler.updateUserAddress"] = userHandler.UpdateUserAddress
-	return handlers
+	return node.NewRegistrar().
+		Register(userHandler).
+		Handlers()
+}
+
+// Validators 每个路由的请求参数，handler通过 node.Request 获取解析后的参数
+func Validators() map[string]node.Middleware {
+	return map[string]node.Middleware{
+		"userHandler.updateUserAddress": node.Validate[request.UpdateUserAddressReq](biz.RequestDataError),
+	}
 }